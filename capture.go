@@ -0,0 +1,126 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// ErrExceedsOpenToCapture is returned by PartialCapture.Capture when the
+// amount is higher than the open balance of the authorization.
+var ErrExceedsOpenToCapture = errors.New("bluesnap: amount exceeds open to capture")
+
+// PartialCapture captures an AUTH_ONLY transaction in several parts, e.g. one
+// per shipped parcel. Amounts are checked locally against the last known
+// open balance before being sent. It is safe for concurrent use.
+type PartialCapture struct {
+	c    Connector
+	auth card.Response
+
+	mu       sync.Mutex
+	open     money.Money
+	captures []CaptureResult
+}
+
+// CaptureResult is the outcome of a single capture of a PartialCapture.
+type CaptureResult struct {
+	// AuthTransactionID is the transactionId of the captured authorization.
+	AuthTransactionID string
+	Amount            money.Money
+	Response          card.Response
+}
+
+// NewPartialCapture starts capturing auth, the response of an Auth or
+// Retrieve call, whose OpenToCapture is the initial open balance.
+func (c Connector) NewPartialCapture(auth card.Response) (*PartialCapture, error) {
+	if auth.TransactionID == "" {
+		return nil, errors.New("authorization has no transactionId")
+	}
+	open, err := auth.OpenToCaptureMoney()
+	if err != nil {
+		return nil, err
+	}
+	if open.Sign() <= 0 {
+		return nil, fmt.Errorf("authorization %s has nothing open to capture", auth.TransactionID)
+	}
+	return &PartialCapture{c: c, auth: auth, open: open}, nil
+}
+
+// Auth returns the authorization being captured.
+func (p *PartialCapture) Auth() card.Response {
+	return p.auth
+}
+
+// OpenToCapture returns the last known open balance.
+func (p *PartialCapture) OpenToCapture() money.Money {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.open
+}
+
+// Captures returns the successful captures made so far.
+func (p *PartialCapture) Captures() []CaptureResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]CaptureResult(nil), p.captures...)
+}
+
+// Capture captures amount, in the currency of the authorization.
+func (p *PartialCapture) Capture(ctx context.Context, amount money.Money, opts Opts) (CaptureResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if amount.Sign() <= 0 {
+		return CaptureResult{}, fmt.Errorf("invalid capture amount %s", amount)
+	}
+	cmp, err := amount.Cmp(p.open)
+	if err != nil {
+		return CaptureResult{}, err
+	}
+	if cmp > 0 {
+		return CaptureResult{}, fmt.Errorf("%w: %s > %s", ErrExceedsOpenToCapture, amount, p.open)
+	}
+
+	result := CaptureResult{
+		AuthTransactionID: p.auth.TransactionID,
+		Amount:            amount,
+	}
+	req := card.CaptureRequest{TransactionID: p.auth.TransactionID}
+	req.SetMoney(amount)
+	if err := p.c.CaptureContext(ctx, req, &result.Response, opts); err != nil {
+		return CaptureResult{}, err
+	}
+
+	p.open, _ = p.open.Sub(amount)
+	// BlueSnap's own balance wins when reported, zero may mean it is omitted.
+	if result.Response.OpenToCapture.Sign() > 0 {
+		if open, err := result.Response.OpenToCapture.Money(p.open.Currency()); err == nil {
+			p.open = open
+		}
+	}
+	p.captures = append(p.captures, result)
+	return result, nil
+}
+
+// Release reverses the authorization so that the remaining open balance is
+// returned to the shopper. No call is made when nothing is left open.
+func (p *PartialCapture) Release(ctx context.Context, opts Opts) (card.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var resp card.Response
+	if p.open.Sign() <= 0 {
+		return resp, nil
+	}
+	if err := p.c.AuthReversalContext(ctx, card.ReversalRequest{TransactionID: p.auth.TransactionID}, &resp, opts); err != nil {
+		return resp, err
+	}
+	p.open = money.New(0, p.open.Currency())
+	return resp, nil
+}
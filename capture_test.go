@@ -0,0 +1,96 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+func TestPartialCapture(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.Write([]byte(`{"transactionId":"1035511870"}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	ctx := context.Background()
+
+	if _, err := c.NewPartialCapture(card.Response{TransactionID: "1035511869"}); err == nil {
+		t.Error("NewPartialCapture should refuse an authorization with nothing open")
+	}
+	pc, err := c.NewPartialCapture(card.Response{TransactionID: "1035511869", OpenToCapture: "10.5", Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pc.Capture(ctx, usd("4"), Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.AuthTransactionID != "1035511869" || res.Amount.Amount() != "4.00" || res.Response.TransactionID != "1035511870" {
+		t.Errorf("unexpected capture result %+v", res)
+	}
+	if open := pc.OpenToCapture().Amount(); open != "6.50" {
+		t.Errorf("expected 6.50 open to capture, got %s", open)
+	}
+
+	if _, err := pc.Capture(ctx, usd("6.51"), Opts{}); !errors.Is(err, ErrExceedsOpenToCapture) {
+		t.Errorf("expected ErrExceedsOpenToCapture, got %v", err)
+	}
+	if _, err := pc.Capture(ctx, usd("-1"), Opts{}); err == nil {
+		t.Error("Capture should refuse a negative amount")
+	}
+	if _, err := pc.Capture(ctx, money.New(100, "EUR"), Opts{}); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Errorf("invalid captures should not be sent, got %d calls", len(bodies))
+	}
+
+	if _, err := pc.Capture(ctx, usd("2.50"), Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pc.Release(ctx, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if open := pc.OpenToCapture().Amount(); open != "0.00" {
+		t.Errorf("expected nothing open after release, got %s", open)
+	}
+	if _, err := pc.Release(ctx, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"cardTransactionType":"CAPTURE","transactionId":"1035511869","amount":4.00}`,
+		`{"cardTransactionType":"CAPTURE","transactionId":"1035511869","amount":2.50}`,
+		`{"cardTransactionType":"AUTH_REVERSAL","transactionId":"1035511869"}`,
+	}
+	if len(bodies) != len(expected) {
+		t.Fatalf("expected %d calls, got %d", len(expected), len(bodies))
+	}
+	for i := range expected {
+		if bodies[i] != expected[i] {
+			t.Errorf("call %d: expected\n%s, got\n%s", i, expected[i], bodies[i])
+		}
+	}
+	if n := len(pc.Captures()); n != 2 {
+		t.Errorf("expected 2 captures, got %d", n)
+	}
+}
+
+func usd(amount string) money.Money {
+	m, err := money.Parse(amount, "USD")
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
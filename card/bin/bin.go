@@ -0,0 +1,314 @@
+// Package bin detects card brands from the leading digits (the BIN, or IIN)
+// of card numbers and checks, formats and masks card numbers.
+package bin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Brand is a card brand, its values match card.CardType.
+type Brand string
+
+const (
+	Unknown         Brand = ""
+	Visa            Brand = "VISA"
+	Mastercard      Brand = "MASTERCARD"
+	Amex            Brand = "AMEX"
+	Discover        Brand = "DISCOVER"
+	JCB             Brand = "JCB"
+	Diners          Brand = "DINERS"
+	UnionPay        Brand = "CHINA_UNION_PAY"
+	Maestro         Brand = "MAESTRO_UK"
+	Elo             Brand = "ELO"
+	Hipercard       Brand = "HIPERCARD"
+	CartesBancaires Brand = "CARTE_BLEUE"
+)
+
+type brandInfo struct {
+	lengths []int
+	cvv     int
+	// groups are the digit groups of formatted numbers, the last one
+	// taking the remaining digits.
+	groups []int
+}
+
+var brands = map[Brand]brandInfo{
+	Visa:            {[]int{13, 16, 19}, 3, nil},
+	Mastercard:      {[]int{16}, 3, nil},
+	Amex:            {[]int{15}, 4, []int{4, 6, 5}},
+	Discover:        {[]int{16, 17, 18, 19}, 3, nil},
+	JCB:             {[]int{16, 17, 18, 19}, 3, nil},
+	Diners:          {[]int{14, 15, 16, 17, 18, 19}, 3, []int{4, 6, 4}},
+	UnionPay:        {[]int{16, 17, 18, 19}, 3, nil},
+	Maestro:         {[]int{12, 13, 14, 15, 16, 17, 18, 19}, 3, nil},
+	Elo:             {[]int{16}, 3, nil},
+	Hipercard:       {[]int{16, 19}, 3, nil},
+	CartesBancaires: {[]int{16, 19}, 3, nil},
+}
+
+type binRange struct {
+	lo, hi string
+	brand  Brand
+}
+
+// ranges maps BIN ranges to brands, lo and hi being prefixes of the same
+// length. The most specific range matching a number wins, so that e.g. Elo
+// ranges inside the Visa and Discover ones are detected as Elo, and the
+// Discover range 622126-622925 inside the UnionPay range 62 as Discover.
+var ranges = []binRange{
+	{"4", "4", Visa},
+	{"51", "55", Mastercard},
+	{"2221", "2720", Mastercard},
+	{"34", "34", Amex},
+	{"37", "37", Amex},
+	{"6011", "6011", Discover},
+	{"644", "649", Discover},
+	{"65", "65", Discover},
+	{"3528", "3589", JCB},
+	{"300", "305", Diners},
+	{"3095", "3095", Diners},
+	{"36", "36", Diners},
+	{"38", "39", Diners},
+	{"62", "62", UnionPay},
+	{"81", "81", UnionPay},
+	{"622126", "622925", Discover},
+	{"50", "50", Maestro},
+	{"56", "58", Maestro},
+	{"6304", "6304", Maestro},
+	{"6759", "6759", Maestro},
+	{"676", "676", Maestro},
+	{"401178", "401179", Elo},
+	{"431274", "431274", Elo},
+	{"438935", "438935", Elo},
+	{"451416", "451416", Elo},
+	{"457393", "457393", Elo},
+	{"457631", "457632", Elo},
+	{"504175", "504175", Elo},
+	{"506699", "506778", Elo},
+	{"509000", "509999", Elo},
+	{"627780", "627780", Elo},
+	{"636297", "636297", Elo},
+	{"636368", "636368", Elo},
+	{"650031", "650033", Elo},
+	{"650035", "650051", Elo},
+	{"650405", "650439", Elo},
+	{"650485", "650538", Elo},
+	{"650541", "650598", Elo},
+	{"650700", "650718", Elo},
+	{"650720", "650727", Elo},
+	{"650901", "650978", Elo},
+	{"651652", "651679", Elo},
+	{"655000", "655019", Elo},
+	{"655021", "655058", Elo},
+	{"606282", "606282", Hipercard},
+	{"384100", "384100", Hipercard},
+	{"384140", "384140", Hipercard},
+	{"384160", "384160", Hipercard},
+}
+
+// coBadgedRange is a range of cards carrying a local brand on top of the
+// brand of an international network.
+type coBadgedRange struct {
+	binRange
+	network Brand
+}
+
+// coBadged lists the ranges of co-badged cards. Detection of Cartes
+// Bancaires is best effort: CB does not publish its BIN ranges, and the
+// only range known here is 4970, co-badged with Visa. Other CB cards, issued
+// in the ranges of Visa or Mastercard, are detected as such, and routing on
+// CB requires a BIN lookup service.
+var coBadged = []coBadgedRange{
+	{binRange{"4970", "4970", CartesBancaires}, Visa},
+}
+
+// Detect returns the brand of a card number, or Unknown. A prefix of at
+// least 6 digits is enough for most brands. Spaces and dashes are ignored.
+// Cartes Bancaires detection is best effort, most CB cards being detected
+// as the Visa or Mastercard they are co-badged with.
+func Detect(pan string) Brand {
+	b, _ := match(pan)
+	return b
+}
+
+// Brands returns the brands a card number may be processed under: its
+// detected brand followed, for co-badged cards such as Cartes Bancaires, by
+// the brand of the international network, e.g. Visa.
+func Brands(pan string) []Brand {
+	switch b, network := match(pan); {
+	case b == Unknown:
+		return nil
+	case network != Unknown:
+		return []Brand{b, network}
+	default:
+		return []Brand{b}
+	}
+}
+
+// match returns the brand of the most specific range matching pan, and the
+// network brand when the range is co-badged.
+func match(pan string) (brand, network Brand) {
+	pan = Normalize(pan)
+	best := 0
+	for _, r := range ranges {
+		if n := r.match(pan); n > best {
+			best, brand = n, r.brand
+		}
+	}
+	for _, r := range coBadged {
+		if n := r.match(pan); n > best {
+			best, brand, network = n, r.brand, r.network
+		}
+	}
+	return brand, network
+}
+
+// match returns the length of the prefix of pan matching r, or 0.
+func (r binRange) match(pan string) int {
+	n := len(r.lo)
+	if n > len(pan) {
+		return 0
+	}
+	if p := pan[:n]; p >= r.lo && p <= r.hi {
+		return n
+	}
+	return 0
+}
+
+// Valid reports whether b is a known brand.
+func (b Brand) Valid() bool {
+	_, ok := brands[b]
+	return ok
+}
+
+// Lengths returns the valid card number lengths of b, 12 to 19 for unknown
+// brands.
+func (b Brand) Lengths() []int {
+	if info, ok := brands[b]; ok {
+		return append([]int(nil), info.lengths...)
+	}
+	return []int{12, 13, 14, 15, 16, 17, 18, 19}
+}
+
+// ValidLength reports whether n is a valid card number length for b.
+func (b Brand) ValidLength(n int) bool {
+	for _, l := range b.Lengths() {
+		if l == n {
+			return true
+		}
+	}
+	return false
+}
+
+// CVVLength returns the security code length of b: 4 for American Express,
+// 3 otherwise.
+func (b Brand) CVVLength() int {
+	if info, ok := brands[b]; ok {
+		return info.cvv
+	}
+	return 3
+}
+
+// Normalize removes the spaces and dashes of a card number as typed by a
+// shopper.
+func Normalize(pan string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(pan)
+}
+
+// Luhn reports whether pan, digits only, passes the Luhn checksum.
+func Luhn(pan string) bool {
+	if pan == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(pan) - 1; i >= 0; i-- {
+		d := int(pan[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Check validates a card number: digits only, a length valid for its brand
+// and the Luhn checksum. It returns the brand along with the first problem
+// found.
+func Check(pan string) (Brand, error) {
+	pan = Normalize(pan)
+	b := Detect(pan)
+	for _, r := range pan {
+		if r < '0' || r > '9' {
+			return b, errorString("card number must only contain digits")
+		}
+	}
+	if !b.ValidLength(len(pan)) {
+		return b, errorString("invalid card number length " + strconv.Itoa(len(pan)) + " for " + b.name())
+	}
+	if !Luhn(pan) {
+		return b, errorString("card number fails the Luhn check")
+	}
+	return b, nil
+}
+
+func (b Brand) name() string {
+	if b == Unknown {
+		return "unknown brand"
+	}
+	return string(b)
+}
+
+type errorString string
+
+func (e errorString) Error() string {
+	return "bin: " + string(e)
+}
+
+// Format groups the digits of pan as printed on cards of its brand, e.g.
+// "4263 9826 4026 9299" or "3782 822463 10005" for American Express.
+func Format(pan string) string {
+	pan = Normalize(pan)
+	groups := brands[Detect(pan)].groups
+	if groups == nil {
+		groups = []int{4, 4, 4, 4, 4}
+	}
+
+	var b strings.Builder
+	for i, g := range groups {
+		if pan == "" {
+			break
+		}
+		if i == len(groups)-1 || g > len(pan) {
+			g = len(pan)
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(pan[:g])
+		pan = pan[g:]
+	}
+	return b.String()
+}
+
+// Mask keeps the BIN and last four digits of pan, e.g.
+// "426398******9299". Numbers too short to keep both only show their last
+// four digits.
+func Mask(pan string) string {
+	pan = Normalize(pan)
+	switch {
+	case len(pan) >= 13:
+		return pan[:6] + strings.Repeat("*", len(pan)-10) + pan[len(pan)-4:]
+	case len(pan) > 4:
+		return strings.Repeat("*", len(pan)-4) + pan[len(pan)-4:]
+	}
+	return strings.Repeat("*", len(pan))
+}
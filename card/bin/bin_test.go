@@ -0,0 +1,161 @@
+package bin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		pan   string
+		brand Brand
+	}{
+		{"4263982640269299", Visa},
+		{"4222222222222", Visa},
+		{"5555555555554444", Mastercard},
+		{"2223003122003222", Mastercard},
+		{"2720990000000000", Mastercard},
+		{"2721000000000000", Unknown},
+		{"378282246310005", Amex},
+		{"341111111111111", Amex},
+		{"6011000990139424", Discover},
+		{"6445644564456445", Discover},
+		{"6221260000000000", Discover},
+		{"3530111333300000", JCB},
+		{"30569309025904", Diners},
+		{"36227206271667", Diners},
+		{"6200000000000005", UnionPay},
+		{"6205500000000000004", UnionPay},
+		{"6759649826438453", Maestro},
+		{"5018000000000009", Maestro},
+		{"6362970000457013", Elo},
+		{"5066991111111118", Elo},
+		{"4011780000000000", Elo},
+		{"6062826786276634", Hipercard},
+		{"3841001111222233334", Hipercard},
+		{"6221250000000000", UnionPay},
+		{"6229250000000000", Discover},
+		{"6229260000000000", UnionPay},
+		{"4970101122334422", CartesBancaires},
+		{"4969999999999999", Visa},
+		{"4971000000000000", Visa},
+		{"4263 9826-4026 9299", Visa},
+		{"4", Visa},
+		{"9999999999999999", Unknown},
+		{"", Unknown},
+	}
+	for _, test := range tests {
+		if got := Detect(test.pan); got != test.brand {
+			t.Errorf("Detect(%q): expected %q, got %q", test.pan, test.brand, got)
+		}
+	}
+}
+
+func TestBrands(t *testing.T) {
+	tests := []struct {
+		pan    string
+		brands []Brand
+	}{
+		{"4263982640269299", []Brand{Visa}},
+		{"4970101122334422", []Brand{CartesBancaires, Visa}},
+		{"4011780000000000", []Brand{Elo}},
+		{"9999999999999999", nil},
+	}
+	for _, test := range tests {
+		if got := Brands(test.pan); !reflect.DeepEqual(got, test.brands) {
+			t.Errorf("Brands(%q): expected %v, got %v", test.pan, test.brands, got)
+		}
+	}
+}
+
+func TestBrandLengths(t *testing.T) {
+	tests := []struct {
+		brand   Brand
+		lengths []int
+		cvv     int
+	}{
+		{Visa, []int{13, 16, 19}, 3},
+		{Mastercard, []int{16}, 3},
+		{Amex, []int{15}, 4},
+		{Diners, []int{14, 15, 16, 17, 18, 19}, 3},
+		{Maestro, []int{12, 13, 14, 15, 16, 17, 18, 19}, 3},
+		{Unknown, []int{12, 13, 14, 15, 16, 17, 18, 19}, 3},
+	}
+	for _, test := range tests {
+		if got := test.brand.Lengths(); !reflect.DeepEqual(got, test.lengths) {
+			t.Errorf("%q: expected lengths %v, got %v", test.brand, test.lengths, got)
+		}
+		if got := test.brand.CVVLength(); got != test.cvv {
+			t.Errorf("%q: expected CVV length %d, got %d", test.brand, test.cvv, got)
+		}
+	}
+	if !Amex.ValidLength(15) || Amex.ValidLength(16) {
+		t.Error("unexpected Amex length validity")
+	}
+	if !Elo.Valid() || Brand("VISA_ELECTRON").Valid() || Unknown.Valid() {
+		t.Error("unexpected brand validity")
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		pan   string
+		valid bool
+	}{
+		{"4263982640269299", true},
+		{"378282246310005", true},
+		{"6205500000000000004", true},
+		{"4263982640269290", false},
+		{"0", true},
+		{"", false},
+		{"4263a82640269299", false},
+	}
+	for _, test := range tests {
+		if got := Luhn(test.pan); got != test.valid {
+			t.Errorf("Luhn(%q): expected %v, got %v", test.pan, test.valid, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		pan   string
+		brand Brand
+		valid bool
+	}{
+		{"4263 9826 4026 9299", Visa, true},
+		{"378282246310005", Amex, true},
+		{"3782822463100050", Amex, false},
+		{"4263982640269290", Visa, false},
+		{"42639826402692x9", Visa, false},
+		{"555555555555444", Mastercard, false},
+	}
+	for _, test := range tests {
+		b, err := Check(test.pan)
+		if b != test.brand || (err == nil) != test.valid {
+			t.Errorf("Check(%q): expected %q and valid %v, got %q and %v", test.pan, test.brand, test.valid, b, err)
+		}
+	}
+}
+
+func TestFormatAndMask(t *testing.T) {
+	tests := []struct {
+		pan, formatted, masked string
+	}{
+		{"4263982640269299", "4263 9826 4026 9299", "426398******9299"},
+		{"378282246310005", "3782 822463 10005", "378282*****0005"},
+		{"30569309025904", "3056 930902 5904", "305693****5904"},
+		{"6205500000000000004", "6205 5000 0000 0000 004", "620550*********0004"},
+		{"4263-9826-4026-9299", "4263 9826 4026 9299", "426398******9299"},
+		{"123456", "1234 56", "**3456"},
+		{"", "", ""},
+	}
+	for _, test := range tests {
+		if got := Format(test.pan); got != test.formatted {
+			t.Errorf("Format(%q): expected %q, got %q", test.pan, test.formatted, got)
+		}
+		if got := Mask(test.pan); got != test.masked {
+			t.Errorf("Mask(%q): expected %q, got %q", test.pan, test.masked, got)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package card
+
+// Enumerations of the card API. Values unknown to this package are kept as
+// is when decoded, so that new BlueSnap values do not break decoding; use
+// Valid to tell them apart.
+
+type TransactionType string
+
+const (
+	TransactionAuthOnly     TransactionType = "AUTH_ONLY"
+	TransactionAuthCapture  TransactionType = "AUTH_CAPTURE"
+	TransactionCapture      TransactionType = "CAPTURE"
+	TransactionAuthReversal TransactionType = "AUTH_REVERSAL"
+	TransactionUpdateAuth   TransactionType = "UPDATE_AUTH"
+)
+
+func (t TransactionType) Valid() bool {
+	switch t {
+	case TransactionAuthOnly, TransactionAuthCapture, TransactionCapture, TransactionAuthReversal, TransactionUpdateAuth:
+		return true
+	}
+	return false
+}
+
+type ProcessingStatus string
+
+const (
+	ProcessingSuccess ProcessingStatus = "success"
+	ProcessingPending ProcessingStatus = "pending"
+	ProcessingFailure ProcessingStatus = "failure"
+)
+
+func (s ProcessingStatus) Valid() bool {
+	switch s {
+	case ProcessingSuccess, ProcessingPending, ProcessingFailure:
+		return true
+	}
+	return false
+}
+
+// CVVResponseCode is the result of the security code check.
+type CVVResponseCode string
+
+const (
+	CVVMatch         CVVResponseCode = "MA"
+	CVVNoMatch       CVVResponseCode = "NM"
+	CVVNotChecked    CVVResponseCode = "NC"
+	CVVNoData        CVVResponseCode = "ND"
+	CVVNotProcessed  CVVResponseCode = "NP"
+	CVVNotRegistered CVVResponseCode = "NR"
+	CVVNotSupported  CVVResponseCode = "NS"
+)
+
+var cvvDescriptions = map[CVVResponseCode]string{
+	CVVMatch:         "CVV match",
+	CVVNoMatch:       "CVV does not match",
+	CVVNotChecked:    "CVV not checked",
+	CVVNoData:        "CVV not provided",
+	CVVNotProcessed:  "CVV not processed",
+	CVVNotRegistered: "Issuer not registered for CVV checks",
+	CVVNotSupported:  "CVV checks not supported by the issuer",
+}
+
+func (c CVVResponseCode) Valid() bool {
+	_, ok := cvvDescriptions[c]
+	return ok
+}
+
+// Description explains c, e.g. "CVV match" for MA. Unknown codes are
+// described as such.
+func (c CVVResponseCode) Description() string {
+	if d, ok := cvvDescriptions[c]; ok {
+		return d
+	}
+	return "Unknown CVV response code " + string(c)
+}
+
+// AVSResponseCode is the result of the address verification. The
+// per-field codes of ProcessingInfo are M, N or U, the overall code of
+// Response may be any of the constants.
+type AVSResponseCode string
+
+const (
+	AVSMatch       AVSResponseCode = "M"
+	AVSNoMatch     AVSResponseCode = "N"
+	AVSUnavailable AVSResponseCode = "U"
+
+	AVSAddressOnly        AVSResponseCode = "A"
+	AVSAddressOnlyIntl    AVSResponseCode = "B"
+	AVSNotVerifiedIntl    AVSResponseCode = "C"
+	AVSMatchIntl          AVSResponseCode = "D"
+	AVSError              AVSResponseCode = "E"
+	AVSMatchUK            AVSResponseCode = "F"
+	AVSNotSupportedIntl   AVSResponseCode = "G"
+	AVSNotVerified        AVSResponseCode = "I"
+	AVSPostalCodeOnlyIntl AVSResponseCode = "P"
+	AVSRetry              AVSResponseCode = "R"
+	AVSNotSupported       AVSResponseCode = "S"
+	AVSNineDigitZipOnly   AVSResponseCode = "W"
+	AVSNineDigitZipMatch  AVSResponseCode = "X"
+	AVSFiveDigitZipMatch  AVSResponseCode = "Y"
+	AVSFiveDigitZipOnly   AVSResponseCode = "Z"
+)
+
+var avsDescriptions = map[AVSResponseCode]string{
+	AVSMatch:              "Match",
+	AVSNoMatch:            "No match",
+	AVSUnavailable:        "Address information unavailable",
+	AVSAddressOnly:        "Street address matches, postal code does not",
+	AVSAddressOnlyIntl:    "Street address matches, postal code not verified (international)",
+	AVSNotVerifiedIntl:    "Street address and postal code not verified (international)",
+	AVSMatchIntl:          "Street address and postal code match (international)",
+	AVSError:              "AVS error",
+	AVSMatchUK:            "Street address and postal code match (UK)",
+	AVSNotSupportedIntl:   "AVS not supported by the non-US issuer",
+	AVSNotVerified:        "Address information not verified (international)",
+	AVSPostalCodeOnlyIntl: "Postal code matches, street address not verified (international)",
+	AVSRetry:              "Issuer system unavailable, retry",
+	AVSNotSupported:       "AVS not supported by the issuer",
+	AVSNineDigitZipOnly:   "Nine-digit ZIP code matches, street address does not",
+	AVSNineDigitZipMatch:  "Street address and nine-digit ZIP code match",
+	AVSFiveDigitZipMatch:  "Street address and five-digit ZIP code match",
+	AVSFiveDigitZipOnly:   "Five-digit ZIP code matches, street address does not",
+}
+
+func (c AVSResponseCode) Valid() bool {
+	_, ok := avsDescriptions[c]
+	return ok
+}
+
+// Description explains c, e.g. "Match" for M. Unknown codes are described
+// as such.
+func (c AVSResponseCode) Description() string {
+	if d, ok := avsDescriptions[c]; ok {
+		return d
+	}
+	return "Unknown AVS response code " + string(c)
+}
+
+// TransactionInitiator tells whether the shopper or the merchant initiated a
+// transaction.
+type TransactionInitiator string
+
+const (
+	InitiatorShopper  TransactionInitiator = "SHOPPER"
+	InitiatorMerchant TransactionInitiator = "MERCHANT"
+)
+
+func (i TransactionInitiator) Valid() bool {
+	return i == InitiatorShopper || i == InitiatorMerchant
+}
+
+type TransactionOrderSource string
+
+const (
+	OrderSourceECommerce TransactionOrderSource = "ECOMMERCE"
+	OrderSourceMOTO      TransactionOrderSource = "MOTO"
+)
+
+func (s TransactionOrderSource) Valid() bool {
+	return s == OrderSourceECommerce || s == OrderSourceMOTO
+}
+
+type RecurringTransaction string
+
+const (
+	RecurringTransactionECommerce RecurringTransaction = "ECOMMERCE"
+	RecurringTransactionRecurring RecurringTransaction = "RECURRING"
+)
+
+func (r RecurringTransaction) Valid() bool {
+	return r == RecurringTransactionECommerce || r == RecurringTransactionRecurring
+}
+
+// CardType is a card brand, see package bin to detect it from a card number.
+type CardType string
+
+const (
+	CardVisa            CardType = "VISA"
+	CardMastercard      CardType = "MASTERCARD"
+	CardAmex            CardType = "AMEX"
+	CardDiscover        CardType = "DISCOVER"
+	CardJCB             CardType = "JCB"
+	CardDiners          CardType = "DINERS"
+	CardUnionPay        CardType = "CHINA_UNION_PAY"
+	CardMaestro         CardType = "MAESTRO_UK"
+	CardElo             CardType = "ELO"
+	CardHipercard       CardType = "HIPERCARD"
+	CardCartesBancaires CardType = "CARTE_BLEUE"
+)
+
+func (t CardType) Valid() bool {
+	switch t {
+	case CardVisa, CardMastercard, CardAmex, CardDiscover, CardJCB, CardDiners, CardUnionPay, CardMaestro, CardElo, CardHipercard, CardCartesBancaires:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package card
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnumsJSON(t *testing.T) {
+	data := []byte(`{"cardTransactionType":"AUTH_ONLY","avsResponseCode":"Y","processingInfo":{"processingStatus":"success","cvvResponseCode":"MA","avsResponseCodeZip":"M","avsResponseCodeAddress":"N","avsResponseCodeName":"Q"},"creditCard":{"cardType":"NEW_BRAND"}}`)
+
+	var r Response
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.CardTransactionType != TransactionAuthOnly || r.ProcessingInfo.ProcessingStatus != ProcessingSuccess || r.ProcessingInfo.CVVResponseCode != CVVMatch {
+		t.Errorf("unexpected decoded values %+v", r)
+	}
+	if r.ProcessingInfo.AVSResponseCodeName != "Q" || r.ProcessingInfo.AVSResponseCodeName.Valid() {
+		t.Errorf("unknown AVS code should be kept and invalid, got %q", r.ProcessingInfo.AVSResponseCodeName)
+	}
+	if r.CreditCard.CardType != "NEW_BRAND" || r.CreditCard.CardType.Valid() {
+		t.Errorf("unknown card type should be kept and invalid, got %q", r.CreditCard.CardType)
+	}
+
+	out, err := json.Marshal(Request{CardTransactionType: TransactionAuthCapture, TransactionInitiator: "FUTURE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Request
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.CardTransactionType != TransactionAuthCapture || back.TransactionInitiator != "FUTURE" {
+		t.Errorf("values should round-trip, got %+v", back)
+	}
+}
+
+func TestEnumsValid(t *testing.T) {
+	valid := []interface{ Valid() bool }{
+		TransactionUpdateAuth, ProcessingPending, CVVNoData, AVSFiveDigitZipMatch,
+		InitiatorMerchant, OrderSourceMOTO, RecurringTransactionRecurring, CardCartesBancaires,
+	}
+	for _, v := range valid {
+		if !v.Valid() {
+			t.Errorf("%v should be valid", v)
+		}
+	}
+	invalid := []interface{ Valid() bool }{
+		TransactionType("auth_only"), ProcessingStatus(""), CVVResponseCode("XX"), AVSResponseCode("Q"),
+		TransactionInitiator("SYSTEM"), TransactionOrderSource(""), RecurringTransaction("YES"), CardType("VISA_ELECTRON"),
+	}
+	for _, v := range invalid {
+		if v.Valid() {
+			t.Errorf("%v should be invalid", v)
+		}
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	tests := []struct {
+		got, expected string
+	}{
+		{CVVMatch.Description(), "CVV match"},
+		{CVVNoMatch.Description(), "CVV does not match"},
+		{CVVResponseCode("ZZ").Description(), "Unknown CVV response code ZZ"},
+		{AVSMatch.Description(), "Match"},
+		{AVSFiveDigitZipOnly.Description(), "Five-digit ZIP code matches, street address does not"},
+		{AVSResponseCode("Q").Description(), "Unknown AVS response code Q"},
+	}
+	for _, test := range tests {
+		if test.got != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, test.got)
+		}
+	}
+}
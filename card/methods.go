@@ -12,10 +12,81 @@ func (r Request) Method() string {
 	return Method
 }
 
+func (r CaptureRequest) ToJSON() ([]byte, error) {
+	type request CaptureRequest
+	return json.Marshal(struct {
+		CardTransactionType TransactionType `json:"cardTransactionType"`
+		request
+	}{TransactionCapture, request(r)})
+}
+
+func (r CaptureRequest) Method() string {
+	return Method
+}
+
+func (r ReversalRequest) ToJSON() ([]byte, error) {
+	type request ReversalRequest
+	return json.Marshal(struct {
+		CardTransactionType TransactionType `json:"cardTransactionType"`
+		request
+	}{TransactionAuthReversal, request(r)})
+}
+
+func (r ReversalRequest) Method() string {
+	return Method
+}
+
+func (r UpdateAuthRequest) ToJSON() ([]byte, error) {
+	type request UpdateAuthRequest
+	return json.Marshal(struct {
+		CardTransactionType TransactionType `json:"cardTransactionType"`
+		request
+	}{TransactionUpdateAuth, request(r)})
+}
+
+func (r UpdateAuthRequest) Method() string {
+	return Method
+}
+
+// IdempotencyKey identifies the transaction by its merchantTransactionId.
+func (r Request) IdempotencyKey() string {
+	return r.MerchantTransactionID
+}
+
+// FromJSON decodes r, tracking the amount held by authorizations in
+// AuthorizedAmount when BlueSnap does not report it.
 func (r *Response) FromJSON(data []byte) error {
-	return json.Unmarshal(data, r)
+	if err := json.Unmarshal(data, r); err != nil {
+		return err
+	}
+	if r.AuthorizedAmount.IsZero() && (r.CardTransactionType == TransactionAuthOnly || r.CardTransactionType == TransactionUpdateAuth) {
+		r.AuthorizedAmount = r.Amount
+	}
+	return nil
 }
 
 func (r Response) Method() string {
 	return Method
 }
+
+// MarkRecovered flags r as an existing transaction found after an ambiguous
+// failure instead of one created by the call.
+func (r *Response) MarkRecovered() {
+	r.Recovered = true
+}
+
+func (r RefundRequest) ToJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r RefundRequest) Method() string {
+	return Method
+}
+
+func (r *RefundResponse) FromJSON(data []byte) error {
+	return json.Unmarshal(data, r)
+}
+
+func (r RefundResponse) Method() string {
+	return Method
+}
@@ -0,0 +1,99 @@
+package card
+
+import "github.com/metricsglobal/bluesnap/money"
+
+// Money returns the amount of r in its currency.
+func (r Request) Money() (money.Money, error) {
+	return money.Parse(string(r.Amount), money.Currency(r.Currency))
+}
+
+// SetMoney sets the amount and currency of r.
+func (r *Request) SetMoney(m money.Money) {
+	r.Amount = money.Decimal(m.Amount())
+	r.Currency = string(m.Currency())
+}
+
+// SetMoney sets the captured amount, which must be in the currency of the
+// authorization.
+func (r *CaptureRequest) SetMoney(m money.Money) {
+	r.Amount = money.Decimal(m.Amount())
+	r.Currency = m.Currency()
+}
+
+// SetMoney sets the new authorized amount, which must be in the currency of
+// the authorization.
+func (r *UpdateAuthRequest) SetMoney(m money.Money) {
+	r.Amount = money.Decimal(m.Amount())
+	r.Currency = m.Currency()
+}
+
+// SetMoney sets the refunded amount, which must be in the currency of the
+// transaction.
+func (r *RefundRequest) SetMoney(m money.Money) {
+	r.Amount = money.Decimal(m.Amount())
+	r.Currency = m.Currency()
+}
+
+// SetTaxMoney sets the refunded tax amount.
+func (r *RefundRequest) SetTaxMoney(m money.Money) {
+	r.TaxAmount = money.Decimal(m.Amount())
+	r.Currency = m.Currency()
+}
+
+func (r Response) AmountMoney() (money.Money, error) {
+	return r.Amount.Money(money.Currency(r.Currency))
+}
+
+func (r Response) OpenToCaptureMoney() (money.Money, error) {
+	return r.OpenToCapture.Money(money.Currency(r.Currency))
+}
+
+func (r Response) AuthorizedMoney() (money.Money, error) {
+	return r.AuthorizedAmount.Money(money.Currency(r.Currency))
+}
+
+func (r Response) USDMoney() (money.Money, error) {
+	return r.USDAmount.Money("USD")
+}
+
+func (r RefundResponse) AmountMoney() (money.Money, error) {
+	return r.Amount.Money(money.Currency(r.Currency))
+}
+
+func (r RefundResponse) TaxMoney() (money.Money, error) {
+	return r.TaxAmount.Money(money.Currency(r.Currency))
+}
+
+func (r RefundResponse) VendorMoney() (money.Money, error) {
+	return r.VendorAmount.Money(money.Currency(r.Currency))
+}
+
+// BalanceMoney returns the amount left to refund, in the currency c of the
+// transaction.
+func (r Refunds) BalanceMoney(c money.Currency) (money.Money, error) {
+	return r.BalanceAmount.Money(c)
+}
+
+func (r Refunds) TaxBalanceMoney(c money.Currency) (money.Money, error) {
+	return r.TaxBalanceAmount.Money(c)
+}
+
+func (r Refunds) VendorBalanceMoney(c money.Currency) (money.Money, error) {
+	return r.VendorBalanceAmount.Money(c)
+}
+
+func (v VendorBalanceInfo) Money(c money.Currency) (money.Money, error) {
+	return v.VendorAmount.Money(c)
+}
+
+func (v VendorRefundInfo) Money(c money.Currency) (money.Money, error) {
+	return v.VendorAmount.Money(c)
+}
+
+func (v *VendorRefundInfo) SetMoney(m money.Money) {
+	v.VendorAmount = money.Decimal(m.Amount())
+}
+
+func (c Chargeback) Money() (money.Money, error) {
+	return c.Amount.Money(money.Currency(c.Currency))
+}
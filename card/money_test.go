@@ -0,0 +1,96 @@
+package card
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+func TestLevel3FractionalAmounts(t *testing.T) {
+	l3 := Level3DataRequest{
+		SalesTaxAmount: money.New(1250, "USD").Decimal(),
+		TaxRate:        "8.25",
+		Level3DataItems: []Level3DataItem{{
+			UnitCost:      "4.99",
+			LineItemTotal: "9.98",
+			ItemQuantity:  2,
+		}},
+	}
+	data, err := json.Marshal(l3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"salesTaxAmount":12.5,"taxRate":8.25,"level3DataItems":[{"lineItemTotal":9.98,"itemQuantity":2,"unitCost":4.99}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	var got Level3DataResponse
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.SalesTaxAmount != "12.5" || got.TaxRate != "8.25" || got.Level3DataItems[0].UnitCost != "4.99" {
+		t.Errorf("unexpected round trip %+v", got)
+	}
+	if m, err := got.Level3DataItems[0].LineItemTotal.Money("USD"); err != nil || m != money.New(998, "USD") {
+		t.Errorf("expected 9.98 USD, got %s (%v)", m, err)
+	}
+}
+
+func TestResponseAmounts(t *testing.T) {
+	var r Response
+	err := r.FromJSON([]byte(`{"amount":12.50,"usdAmount":13.1,"openToCapture":0.3,"currency":"KWD","cardTransactionType":"AUTH_ONLY",
+		"refunds":{"balanceAmount":10.105,"refund":[{"amount":2.395,"taxAmount":0.001,"currency":"KWD"}],
+			"vendorsBalanceInfo":{"vendorBalanceInfo":[{"vendorId":1,"vendorAmount":"1.250"}]}},
+		"chargebacks":{"chargeback":[{"amount":"0.5","currency":"KWD"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		get      func() (money.Money, error)
+		expected money.Money
+	}{
+		{"amount", r.AmountMoney, money.New(12500, "KWD")},
+		{"authorized", r.AuthorizedMoney, money.New(12500, "KWD")},
+		{"open to capture", r.OpenToCaptureMoney, money.New(300, "KWD")},
+		{"usd", r.USDMoney, money.New(1310, "USD")},
+		{"balance", func() (money.Money, error) { return r.Refunds.BalanceMoney("KWD") }, money.New(10105, "KWD")},
+		{"refund", r.Refunds.Refund[0].AmountMoney, money.New(2395, "KWD")},
+		{"refund tax", r.Refunds.Refund[0].TaxMoney, money.New(1, "KWD")},
+		{"vendor balance", func() (money.Money, error) { return r.Refunds.VendorsBalanceInfo.VendorBalanceInfo[0].Money("KWD") }, money.New(1250, "KWD")},
+		{"chargeback", r.Chargebacks.Chargeback[0].Money, money.New(500, "KWD")},
+	}
+	for _, test := range tests {
+		m, err := test.get()
+		if err != nil || m != test.expected {
+			t.Errorf("%s: expected %s, got %s (%v)", test.name, test.expected, m, err)
+		}
+	}
+}
+
+func TestRequestAmounts(t *testing.T) {
+	r := RefundRequest{VendorsRefundInfo: &VendorsRefundInfo{VendorRefundInfo: []VendorRefundInfo{{VendorID: 1}}}}
+	r.SetMoney(money.New(1500, "JPY"))
+	r.SetTaxMoney(money.New(150, "JPY"))
+	r.VendorsRefundInfo.VendorRefundInfo[0].SetMoney(money.New(300, "JPY"))
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"amount":1500,"taxAmount":150,"vendorsRefundInfo":{"vendorRefundInfo":[{"vendorId":1,"vendorAmount":300}]}}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	req := Request{}
+	req.SetMoney(money.New(1005, "KWD"))
+	if req.Amount != "1.005" || req.Currency != "KWD" {
+		t.Errorf("unexpected amount %s %s", req.Amount, req.Currency)
+	}
+	if m, err := req.Money(); err != nil || m != money.New(1005, "KWD") {
+		t.Errorf("expected 1.005 KWD, got %s (%v)", m, err)
+	}
+}
@@ -0,0 +1,51 @@
+package card
+
+import "strings"
+
+// countries lists the ISO 3166-1 alpha-2 country codes.
+var countries = codeSet(`
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL
+BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV
+CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD
+GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM
+IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK
+LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW
+MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR
+PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS
+ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY
+UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`)
+
+// states lists the state codes BlueSnap requires for the countries that have
+// them.
+var states = map[string]map[string]bool{
+	"US": codeSet(`
+AL AK AZ AR CA CO CT DE DC FL GA HI ID IL IN IA KS KY LA ME MD MA MI MN MS MO
+MT NE NV NH NJ NM NY NC ND OH OK OR PA RI SC SD TN TX UT VT VA WA WV WI WY AS
+GU MP PR VI UM AA AE AP`),
+	"CA": codeSet(`AB BC MB NB NL NS NT NU ON PE QC SK YT`),
+}
+
+func codeSet(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, c := range strings.Fields(s) {
+		m[c] = true
+	}
+	return m
+}
+
+func validCountry(c string) bool {
+	return countries[strings.ToUpper(c)]
+}
+
+// validState reports whether state is valid in country, any state being
+// accepted in countries without a list.
+func validState(country, state string) bool {
+	list, ok := states[strings.ToUpper(country)]
+	return !ok || list[strings.ToUpper(state)]
+}
+
+// requiresState reports whether BlueSnap requires a state in country.
+func requiresState(country string) bool {
+	_, ok := states[strings.ToUpper(country)]
+	return ok
+}
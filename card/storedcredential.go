@@ -0,0 +1,128 @@
+package card
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// ErrCredentialIncomplete is returned, wrapped, with the partial stored
+// credential of a transaction that succeeded but did not return a vaulted
+// shopper or a network transaction ID. The shopper was charged, so the
+// transaction must not be retried.
+var ErrCredentialIncomplete = errors.New("card: transaction succeeded, credential incomplete")
+
+// NetworkTransactionInfo links a merchant-initiated transaction to the first,
+// shopper-initiated, transaction of its stored credential.
+type NetworkTransactionInfo struct {
+	OriginalNetworkTransactionID string `json:"originalNetworkTransactionId"`
+}
+
+// StoredCredentialUsage is the agreement under which a card is stored for
+// merchant-initiated transactions, which the card networks require to be
+// declared.
+type StoredCredentialUsage string
+
+const (
+	// UsageRecurring charges at a fixed interval, e.g. a subscription.
+	UsageRecurring StoredCredentialUsage = "RECURRING"
+	// UsageUnscheduled charges at times and amounts that are not fixed in
+	// advance, e.g. an automatic top-up.
+	UsageUnscheduled StoredCredentialUsage = "UNSCHEDULED"
+	// UsageInstallment splits a single purchase into a known number of
+	// payments.
+	UsageInstallment StoredCredentialUsage = "INSTALLMENT"
+)
+
+func (u StoredCredentialUsage) Valid() bool {
+	return u == UsageRecurring || u == UsageUnscheduled || u == UsageInstallment
+}
+
+// recurringTransaction returns the recurringTransaction flag of the
+// merchant-initiated transactions of u.
+func (u StoredCredentialUsage) recurringTransaction() RecurringTransaction {
+	if u == UsageUnscheduled {
+		return RecurringTransactionECommerce
+	}
+	return RecurringTransactionRecurring
+}
+
+// StoredCredential is what a merchant keeps of the first, shopper-initiated,
+// transaction made with a card to make merchant-initiated transactions with
+// it later. See Response.StoredCredential.
+type StoredCredential struct {
+	Usage              StoredCredentialUsage
+	VaultedShopperID   int64
+	CardLastFourDigits string
+	CardType           CardType
+	// NetworkTransactionID is the ID the card network gave to the first
+	// transaction, sent with each merchant-initiated one.
+	NetworkTransactionID string
+}
+
+// SetInitialStoredCredential flags r as the first, shopper-initiated,
+// transaction of a card stored for later use. The card is stored in a vaulted
+// shopper unless r already charges one.
+func (r *Request) SetInitialStoredCredential() {
+	r.TransactionInitiator = InitiatorShopper
+	r.RecurringTransaction = RecurringTransactionECommerce
+	if r.VaultedShopperID == 0 {
+		r.StoreCard = true
+	}
+}
+
+// StoredCredential returns the stored credential created by r, the response to
+// a request flagged with SetInitialStoredCredential. When the transaction
+// succeeded without a vaulted shopper or network transaction ID, the partial
+// credential is returned with an error wrapping ErrCredentialIncomplete.
+func (r Response) StoredCredential(u StoredCredentialUsage) (StoredCredential, error) {
+	if !u.Valid() {
+		return StoredCredential{}, fmt.Errorf("card: unknown stored credential usage %q", u)
+	}
+	if r.ProcessingInfo.ProcessingStatus != ProcessingSuccess {
+		return StoredCredential{}, fmt.Errorf("card: transaction %s did not succeed", r.TransactionID)
+	}
+	cred := StoredCredential{
+		Usage:                u,
+		VaultedShopperID:     r.VaultedShopperID,
+		CardLastFourDigits:   r.CreditCard.CardLastFourDigits,
+		CardType:             r.CreditCard.CardType,
+		NetworkTransactionID: r.ProcessingInfo.NetworkTransactionId,
+	}
+	if r.VaultedShopperID == 0 {
+		return cred, fmt.Errorf("%w: transaction %s did not store the card in a vaulted shopper", ErrCredentialIncomplete, r.TransactionID)
+	}
+	if r.ProcessingInfo.NetworkTransactionId == "" {
+		return cred, fmt.Errorf("%w: transaction %s has no networkTransactionId", ErrCredentialIncomplete, r.TransactionID)
+	}
+	return cred, nil
+}
+
+// MerchantInitiatedRequest returns the Sale or Auth request charging amount
+// to the stored card, t being AUTH_CAPTURE or AUTH_ONLY. It is flagged as
+// RECURRING for recurring and installment usages, and references the network
+// transaction ID of the first transaction.
+func (s StoredCredential) MerchantInitiatedRequest(t TransactionType, amount money.Money) (Request, error) {
+	if !s.Usage.Valid() {
+		return Request{}, fmt.Errorf("card: unknown stored credential usage %q", s.Usage)
+	}
+	if s.VaultedShopperID == 0 {
+		return Request{}, errors.New("card: stored credential has no vaultedShopperId")
+	}
+
+	r := Request{
+		CardTransactionType:  t,
+		VaultedShopperID:     s.VaultedShopperID,
+		TransactionInitiator: InitiatorMerchant,
+		RecurringTransaction: s.Usage.recurringTransaction(),
+	}
+	if s.CardLastFourDigits != "" {
+		r.CreditCard = &CreditCardRequest{CardLastFourDigits: s.CardLastFourDigits, CardType: s.CardType}
+	}
+	if s.NetworkTransactionID != "" {
+		r.NetworkTransactionInfo = &NetworkTransactionInfo{OriginalNetworkTransactionID: s.NetworkTransactionID}
+	}
+	r.SetMoney(amount)
+	return r, nil
+}
@@ -1,9 +1,19 @@
+// Package card holds the requests and responses of card transactions.
+//
+// All amounts are money.Decimal, exact decimals encoded as JSON numbers,
+// where earlier versions mixed float64, int64 and string fields. This breaks
+// code doing arithmetic on the fields or assigning string variables to them:
+// set amounts with the SetMoney methods or a money.Decimal conversion, and
+// read them with the Money accessors, e.g. Response.AmountMoney, which apply
+// the exponent of the currency of the document.
 package card
 
+import "github.com/metricsglobal/bluesnap/money"
+
 type Request struct {
 	WalletID               int64                        `json:"walletId,omitempty"`
 	Wallet                 *WalletRequest               `json:"wallet,omitempty"`
-	Amount                 string                       `json:"amount,omitempty"`
+	Amount                 money.Decimal                `json:"amount,omitempty"`
 	VaultedShopperID       int64                        `json:"vaultedShopperId,omitempty"`
 	MerchantTransactionID  string                       `json:"merchantTransactionId,omitempty"`
 	SoftDescriptor         string                       `json:"softDescriptor,omitempty"`
@@ -14,48 +24,78 @@ type Request struct {
 	Currency               string                       `json:"currency,omitempty"`
 	TransactionFraudInfo   *TransactionFraudInfoRequest `json:"transactionFraudInfo,omitempty"`
 	CreditCard             *CreditCardRequest           `json:"creditCard,omitempty"`
-	CardTransactionType    string                       `json:"cardTransactionType,omitempty"`
+	CardTransactionType    TransactionType              `json:"cardTransactionType,omitempty"`
 	ThreeDSecure           *ThreeDSecureRequest         `json:"threeDSecure,omitempty"`
 	TransactionMetaData    *TransactionMetadata         `json:"transactionMetaData,omitempty"`
 	PFToken                string                       `json:"pfToken,omitempty"`
 	Level3Data             *Level3DataRequest           `json:"level3Data,omitempty"`
 	StoreCard              bool                         `json:"storeCard,omitempty"`
-	NetworkTransactionInfo map[string]string            `json:"networkTransactionInfo,omitempty"` // TODO
-	TransactionOrderSource string                       `json:"transactionOrderSource,omitempty"`
-	TransactionInitiator   string                       `json:"transactionInitiator,omitempty"`
-	RecurringTransaction   string                       `json:"recurringTransaction"`
+	NetworkTransactionInfo *NetworkTransactionInfo      `json:"networkTransactionInfo,omitempty"`
+	TransactionOrderSource TransactionOrderSource       `json:"transactionOrderSource,omitempty"`
+	TransactionInitiator   TransactionInitiator         `json:"transactionInitiator,omitempty"`
+	RecurringTransaction   RecurringTransaction         `json:"recurringTransaction,omitempty"`
 	TransactionID          string                       `json:"transactionId"`
 }
 
+// CaptureRequest captures a transaction authorized with AUTH_ONLY, partially
+// when Amount is lower than the authorized amount.
+type CaptureRequest struct {
+	TransactionID string             `json:"transactionId"`
+	Amount        money.Decimal      `json:"amount,omitempty"`
+	Level3Data    *Level3DataRequest `json:"level3Data,omitempty"`
+	VendorsInfo   *VendorsInfo       `json:"vendorsInfo,omitempty"`
+	// Currency of the authorization, not sent, lets Validate check the
+	// decimals of Amount. It is set by SetMoney.
+	Currency money.Currency `json:"-"`
+}
+
+// UpdateAuthRequest changes the amount held by an AUTH_ONLY transaction to
+// Amount, which may be higher or lower than the current one.
+type UpdateAuthRequest struct {
+	TransactionID string        `json:"transactionId"`
+	Amount        money.Decimal `json:"amount"`
+	// Currency of the authorization, not sent, lets Validate check the
+	// decimals of Amount. It is set by SetMoney.
+	Currency money.Currency `json:"-"`
+}
+
+// ReversalRequest releases the funds held by an AUTH_ONLY transaction.
+type ReversalRequest struct {
+	TransactionID       string               `json:"transactionId"`
+	TransactionMetaData *TransactionMetadata `json:"transactionMetaData,omitempty"`
+}
+
 type Response struct {
-	Amount                  float64               `json:"Amount"`
-	OpenToCapture           float64               `json:"openToCapture"`
-	VaultedShopperID        int64                 `json:"vaultedShopperId"`
-	MerchantTransactionId   string                `json:"merchantTransactionId"`
+	Amount                  money.Decimal        `json:"Amount"`
+	OpenToCapture           money.Decimal        `json:"openToCapture"`
+	AuthorizedAmount        money.Decimal        `json:"authorizedAmount,omitempty"` // cumulative amount held by an authorization
+	VaultedShopperID        int64                `json:"vaultedShopperId"`
+	MerchantTransactionId   string               `json:"merchantTransactionId"`
 	ProcessingInfo          ProcessingInfo       `json:"processingInfo"`
-	SoftDescriptor          string                `json:"softDescriptor"`
-	DescriptorPhoneNumber   string                `json:"descriptorPhoneNumber"`
-	TaxReference            string                `json:"taxReference"`
+	SoftDescriptor          string               `json:"softDescriptor"`
+	DescriptorPhoneNumber   string               `json:"descriptorPhoneNumber"`
+	TaxReference            string               `json:"taxReference"`
 	CardHolderInfo          CardHolderInfo       `json:"cardHolderInfo"`
-	Currency                string                `json:"currency"`
-	TransactionApprovalDate string                `json:"transactionApprovalDate"`
-	TransactionApprovalTime string                `json:"transactionApprovalTime"`
+	Currency                string               `json:"currency"`
+	TransactionApprovalDate string               `json:"transactionApprovalDate"`
+	TransactionApprovalTime string               `json:"transactionApprovalTime"`
 	FraudResultInfo         FraudResultInfo      `json:"fraudResultInfo"`
 	CreditCard              CreditCardResponse   `json:"creditCard"`
-	CardTransactionType     string                `json:"cardTransactionType"`
+	CardTransactionType     TransactionType      `json:"cardTransactionType"`
 	ThreeDSecure            ThreeDSecureResponse `json:"threeDSecure"`
-	TransactionID           string                `json:"transactionId"`
-	OriginalTransactionID   string                `json:"originalTransactionId"`
-	Chargebacks             Chargebacks           `json:"chargebacks"`
-	Refunds                 Refunds               `json:"refunds"`
+	TransactionID           string               `json:"transactionId"`
+	OriginalTransactionID   string               `json:"originalTransactionId"`
+	Chargebacks             Chargebacks          `json:"chargebacks"`
+	Refunds                 Refunds              `json:"refunds"`
 	Wallet                  WalletResponse       `json:"wallet"`
-	VendorInfo              VendorInfo            `json:"vendorInfo"`
-	VendorsInfo             VendorsInfo           `json:"vendorsInfo"`
+	VendorInfo              VendorInfo           `json:"vendorInfo"`
+	VendorsInfo             VendorsInfo          `json:"vendorsInfo"`
 	Level3Data              Level3DataResponse   `json:"level3Data"`
-	StoreCard               bool                  `json:"storeCard"`
-	TransactionMetadata     TransactionMetadata   `json:"transactionMetaData"`
-	AVSResponseCode         string                `json:"avsResponseCode"`
-	USDAmount               float64               `json:"usdAmount"`
+	StoreCard               bool                 `json:"storeCard"`
+	TransactionMetadata     TransactionMetadata  `json:"transactionMetaData"`
+	AVSResponseCode         AVSResponseCode      `json:"avsResponseCode"`
+	USDAmount               money.Decimal        `json:"usdAmount"`
+	Recovered               bool                 `json:"-"` // set when returned by the duplicate-charge guard
 }
 
 type WalletRequest struct {
@@ -96,15 +136,15 @@ type ShippingContactInfo struct {
 
 // TokenizedCard request and response struct
 type TokenizedCard struct {
-	CardLastFourDigits  string `json:"cardLastFourDigits,omitempty"`
-	CardType            string `json:"cardType,omitempty"`
-	CardSubType         string `json:"cardSubType,omitempty"`
-	BinCategory         string `json:"binCategory,omitempty"`
-	CardRegulated       string `json:"cardRegulated,omitempty"`
-	IssuingCountryCode  string `json:"issuingCountryCode,omitempty"`
-	DPANExpirationMonth string `json:"dpanExpirationMonth,omitempty"`
-	DPANExpirationYear  string `json:"dpanExpirationYear,omitempty"`
-	DPANLastFourDigits  string `json:"dpanLastFourDigits,omitempty"`
+	CardLastFourDigits  string   `json:"cardLastFourDigits,omitempty"`
+	CardType            CardType `json:"cardType,omitempty"`
+	CardSubType         string   `json:"cardSubType,omitempty"`
+	BinCategory         string   `json:"binCategory,omitempty"`
+	CardRegulated       string   `json:"cardRegulated,omitempty"`
+	IssuingCountryCode  string   `json:"issuingCountryCode,omitempty"`
+	DPANExpirationMonth string   `json:"dpanExpirationMonth,omitempty"`
+	DPANExpirationYear  string   `json:"dpanExpirationYear,omitempty"`
+	DPANLastFourDigits  string   `json:"dpanLastFourDigits,omitempty"`
 }
 
 // VendorsInfo request and response struct
@@ -113,9 +153,9 @@ type VendorsInfo struct {
 }
 
 type VendorInfo struct {
-	VendorId          int64   `json:"vendorId,omitempty"`
-	CommissionPercent float64 `json:"commissionPercent,omitempty"`
-	CommissionAmount  int64   `json:"commissionAmount,omitempty"`
+	VendorId          int64         `json:"vendorId,omitempty"`
+	CommissionPercent float64       `json:"commissionPercent,omitempty"`
+	CommissionAmount  money.Decimal `json:"commissionAmount,omitempty"`
 }
 
 // CardHolderInfo request and response struct
@@ -146,11 +186,11 @@ type TransactionFraudInfoRequest struct {
 }
 
 type FraudProduct struct {
-	FraudProductName     string  `json:"fraudProductName,omitempty"`
-	FraudProductDesc     string  `json:"fraudProductDesc,omitempty"`
-	FraudProductType     string  `json:"fraudProductType,omitempty"`
-	FraudProductQuantity int64   `json:"fraudProductQuantity,omitempty"`
-	FraudProductPrice    float64 `json:"fraudProductPrice,omitempty"`
+	FraudProductName     string        `json:"fraudProductName,omitempty"`
+	FraudProductDesc     string        `json:"fraudProductDesc,omitempty"`
+	FraudProductType     string        `json:"fraudProductType,omitempty"`
+	FraudProductQuantity int64         `json:"fraudProductQuantity,omitempty"`
+	FraudProductPrice    money.Decimal `json:"fraudProductPrice,omitempty"`
 }
 
 type EnterpriseUDFs struct {
@@ -163,29 +203,29 @@ type UDF struct {
 }
 
 type CreditCardRequest struct {
-	CardNumber            string `json:"cardNumber,omitempty"`
-	EncryptedCardNumber   string `json:"encryptedCardNumber,omitempty"`
-	CardLastFourDigits    string `json:"cardLastFourDigits,omitempty"`
-	CardType              string `json:"cardType,omitempty"`
-	ExpirationMonth       string `json:"expirationMonth,omitempty"`
-	ExpirationYear        string `json:"expirationYear,omitempty"`
-	SecurityCode          string `json:"securityCode,omitempty"`
-	EncryptedSecurityCode string `json:"encryptedSecurityCode,omitempty"`
-	SecurityCodePfToken   string `json:"securityCodePfToken,omitempty"`
+	CardNumber            string   `json:"cardNumber,omitempty"`
+	EncryptedCardNumber   string   `json:"encryptedCardNumber,omitempty"`
+	CardLastFourDigits    string   `json:"cardLastFourDigits,omitempty"`
+	CardType              CardType `json:"cardType,omitempty"`
+	ExpirationMonth       string   `json:"expirationMonth,omitempty"`
+	ExpirationYear        string   `json:"expirationYear,omitempty"`
+	SecurityCode          string   `json:"securityCode,omitempty"`
+	EncryptedSecurityCode string   `json:"encryptedSecurityCode,omitempty"`
+	SecurityCodePfToken   string   `json:"securityCodePfToken,omitempty"`
 }
 
 type CreditCardResponse struct {
-	CardLastFourDigits string `json:"cardLastFourDigits,omitempty"`
-	CardType           string `json:"cardType,omitempty"`
-	CardSubType        string `json:"cardSubType,omitempty"`
-	CardCategory       string `json:"cardCategory,omitempty"`
-	BinCategory        string `json:"binCategory,omitempty"`
-	BinNumber          string `json:"binNumber,omitempty"`
-	CardRegulated      string `json:"cardRegulated,omitempty"`
-	IssuingBank        string `json:"issuingBank,omitempty"`
-	IssuingCountryCode string `json:"issuingCountryCode,omitempty"`
-	ExpirationMonth    string `json:"expirationMonth,omitempty"`
-	ExpirationYear     string `json:"expirationyear,omitempty"`
+	CardLastFourDigits string   `json:"cardLastFourDigits,omitempty"`
+	CardType           CardType `json:"cardType,omitempty"`
+	CardSubType        string   `json:"cardSubType,omitempty"`
+	CardCategory       string   `json:"cardCategory,omitempty"`
+	BinCategory        string   `json:"binCategory,omitempty"`
+	BinNumber          string   `json:"binNumber,omitempty"`
+	CardRegulated      string   `json:"cardRegulated,omitempty"`
+	IssuingBank        string   `json:"issuingBank,omitempty"`
+	IssuingCountryCode string   `json:"issuingCountryCode,omitempty"`
+	ExpirationMonth    string   `json:"expirationMonth,omitempty"`
+	ExpirationYear     string   `json:"expirationyear,omitempty"`
 }
 
 type ThreeDSecureRequest struct {
@@ -217,15 +257,15 @@ type Metadata struct {
 
 type Level3DataRequest struct {
 	CustomerReferenceNumber string           `json:"customerReferenceNumber,omitempty"`
-	SalesTaxAmount          int64            `json:"salesTaxAmount,omitempty"`
-	FreightAmount           int64            `json:"freightAmount,omitempty"`
-	DutyAmount              int64            `json:"dutyAmount,omitempty"`
+	SalesTaxAmount          money.Decimal    `json:"salesTaxAmount,omitempty"`
+	FreightAmount           money.Decimal    `json:"freightAmount,omitempty"`
+	DutyAmount              money.Decimal    `json:"dutyAmount,omitempty"`
 	DestinationZipCode      string           `json:"destinationZipCode,omitempty"`
 	DestinationCountryCode  string           `json:"destinationCountryCode,omitempty"`
 	ShipFromZipCode         string           `json:"shipFromZipCode,omitempty"`
-	DiscountAmount          int64            `json:"discountAmount,omitempty"`
-	TaxAmount               int64            `json:"taxAmount,omitempty"`
-	TaxRate                 int64            `json:"taxRate,omitempty"`
+	DiscountAmount          money.Decimal    `json:"discountAmount,omitempty"`
+	TaxAmount               money.Decimal    `json:"taxAmount,omitempty"`
+	TaxRate                 money.Decimal    `json:"taxRate,omitempty"`
 	Level3DataItems         []Level3DataItem `json:"level3DataItems,omitempty"`
 }
 
@@ -235,29 +275,29 @@ type Level3DataResponse struct {
 }
 
 type Level3DataItem struct {
-	LineItemTotal     int64  `json:"lineItemTotal,omitempty"`
-	CommodityCode     string `json:"commodityCode,omitempty"`
-	Description       string `json:"description,omitempty"`
-	DiscountAmount    int64  `json:"discountAmount,omitempty"`
-	DiscountIndicator string `json:"discountIndicator,omitempty"`
-	GrossNetIndicator string `json:"grossNetIndicator,omitempty"`
-	ProductCode       string `json:"productCode,omitempty"`
-	ItemQuantity      int64  `json:"itemQuantity,omitempty"`
-	TaxAmount         int64  `json:"taxAmount,omitempty"`
-	TaxRate           int64  `json:"taxRate,omitempty"`
-	TaxType           string `json:"taxType,omitempty"`
-	UnitCost          int64  `json:"unitCost,omitempty"`
-	UnitOfMeasure     string `json:"unitOfMeasure,omitempty"`
+	LineItemTotal     money.Decimal `json:"lineItemTotal,omitempty"`
+	CommodityCode     string        `json:"commodityCode,omitempty"`
+	Description       string        `json:"description,omitempty"`
+	DiscountAmount    money.Decimal `json:"discountAmount,omitempty"`
+	DiscountIndicator string        `json:"discountIndicator,omitempty"`
+	GrossNetIndicator string        `json:"grossNetIndicator,omitempty"`
+	ProductCode       string        `json:"productCode,omitempty"`
+	ItemQuantity      int64         `json:"itemQuantity,omitempty"`
+	TaxAmount         money.Decimal `json:"taxAmount,omitempty"`
+	TaxRate           money.Decimal `json:"taxRate,omitempty"`
+	TaxType           string        `json:"taxType,omitempty"`
+	UnitCost          money.Decimal `json:"unitCost,omitempty"`
+	UnitOfMeasure     string        `json:"unitOfMeasure,omitempty"`
 }
 
 type ProcessingInfo struct {
-	ProcessingStatus       string `json:"processingStatus"`
-	CVVResponseCode        string `json:"cvvResponseCode"`
-	AuthorizationCode      string `json:"authorizationCode"`
-	AVSResponseCodeZip     string `json:"avsResponseCodeZip"`
-	AVSResponseCodeAddress string `json:"avsResponseCodeAddress"`
-	AVSResponseCodeName    string `json:"avsResponseCodeName"`
-	NetworkTransactionId   string `json:"networkTransactionId"`
+	ProcessingStatus       ProcessingStatus `json:"processingStatus"`
+	CVVResponseCode        CVVResponseCode  `json:"cvvResponseCode"`
+	AuthorizationCode      string           `json:"authorizationCode"`
+	AVSResponseCodeZip     AVSResponseCode  `json:"avsResponseCodeZip"`
+	AVSResponseCodeAddress AVSResponseCode  `json:"avsResponseCodeAddress"`
+	AVSResponseCodeName    AVSResponseCode  `json:"avsResponseCodeName"`
+	NetworkTransactionId   string           `json:"networkTransactionId"`
 }
 
 type FraudResultInfo struct {
@@ -266,23 +306,62 @@ type FraudResultInfo struct {
 
 type Refunds struct {
 	Refund              []RefundResponse   `json:"refund"`
-	BalanceAmount       float64            `json:"balanceAmount"`
-	TaxBalanceAmount    float64            `json:"taxBalanceAmount"`
-	VendorBalanceAmount float64            `json:"vendorBalanceAmount"`
+	BalanceAmount       money.Decimal      `json:"balanceAmount"`
+	TaxBalanceAmount    money.Decimal      `json:"taxBalanceAmount"`
+	VendorBalanceAmount money.Decimal      `json:"vendorBalanceAmount"`
 	VendorsBalanceInfo  VendorsBalanceInfo `json:"vendorsBalanceInfo"`
 }
 
+// RefundRequest refunds a transaction, fully when Amount is empty.
+type RefundRequest struct {
+	Amount              money.Decimal        `json:"amount,omitempty"`
+	TaxAmount           money.Decimal        `json:"taxAmount,omitempty"`
+	Reason              string               `json:"reason,omitempty"`
+	CancelSubscriptions *bool                `json:"cancelSubscriptions,omitempty"`
+	VendorsRefundInfo   *VendorsRefundInfo   `json:"vendorsRefundInfo,omitempty"`
+	TransactionMetaData *TransactionMetadata `json:"transactionMetaData,omitempty"`
+	// Currency of the transaction, not sent, lets Validate check the
+	// decimals of the amounts. It is set by SetMoney and SetTaxMoney.
+	Currency money.Currency `json:"-"`
+}
+
 type RefundResponse struct {
-	Amount              float64             `json:"amount"`
-	TaxAmount           float64             `json:"taxAmount"`
+	Amount              money.Decimal       `json:"amount"`
+	TaxAmount           money.Decimal       `json:"taxAmount"`
 	Currency            string              `json:"currency"`
 	Date                string              `json:"date"`
 	RefundTransactionId int64               `json:"refundTransactionId"`
-	VendorAmount        float64             `json:"vendorAmount"`
+	VendorAmount        money.Decimal       `json:"vendorAmount"`
 	VendorsRefundInfo   VendorsRefundInfo   `json:"vendorsRefundInfo"`
 	TransactionMetaData TransactionMetadata `json:"transactionMetaData"`
 	Reason              string              `json:"reason"`
 	CancelSubscriptions bool                `json:"cancelSubscriptions"`
+	RefundStatus        RefundStatus        `json:"refundStatus"`
+}
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "PENDING"
+	RefundSuccess   RefundStatus = "SUCCESS"
+	RefundFailed    RefundStatus = "FAILED"
+	RefundCancelled RefundStatus = "CANCELLED"
+)
+
+// Cancellable reports whether a refund in status s can still be cancelled.
+func (s RefundStatus) Cancellable() bool {
+	return s == RefundPending
+}
+
+// Refund returns the refund of r with the given refundTransactionId. The
+// refunds of a transaction are listed in the Refunds of its retrieval.
+func (r Response) Refund(refundTransactionID int64) (RefundResponse, bool) {
+	for _, refund := range r.Refunds.Refund {
+		if refund.RefundTransactionId == refundTransactionID {
+			return refund, true
+		}
+	}
+	return RefundResponse{}, false
 }
 
 type VendorsBalanceInfo struct {
@@ -290,8 +369,8 @@ type VendorsBalanceInfo struct {
 }
 
 type VendorBalanceInfo struct {
-	VendorID     int64  `json:"vendorId"`
-	VendorAmount string `json:"vendorAmount"`
+	VendorID     int64         `json:"vendorId"`
+	VendorAmount money.Decimal `json:"vendorAmount"`
 }
 
 type VendorsRefundInfo struct {
@@ -299,8 +378,8 @@ type VendorsRefundInfo struct {
 }
 
 type VendorRefundInfo struct {
-	VendorID     int64  `json:"vendorId"`
-	VendorAmount string `json:"vendorAmount"`
+	VendorID     int64         `json:"vendorId"`
+	VendorAmount money.Decimal `json:"vendorAmount"`
 }
 
 type Chargebacks struct {
@@ -308,8 +387,8 @@ type Chargebacks struct {
 }
 
 type Chargeback struct {
-	Amount                  string `json:"amount"`
-	ChargebackTransactionId int64  `json:"chargebackTransactionId"`
-	Currency                string `json:"currency"`
-	Date                    string `json:"date"`
+	Amount                  money.Decimal `json:"amount"`
+	ChargebackTransactionId int64         `json:"chargebackTransactionId"`
+	Currency                string        `json:"currency"`
+	Date                    string        `json:"date"`
 }
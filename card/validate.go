@@ -0,0 +1,375 @@
+package card
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metricsglobal/bluesnap/card/bin"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// FieldError describes an invalid field of a request, Field being its JSON
+// path, e.g. "creditCard.cardNumber".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists every invalid field of a request.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return "card: invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Has reports whether field is among the invalid fields.
+func (e *ValidationError) Has(field string) bool {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends an invalid field to e, for the Validate methods of requests
+// built on card types.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{field, message})
+}
+
+// Merge adds the invalid fields of err, returned by the Validate method of a
+// nested value, under path. Other errors are added on path itself.
+func (e *ValidationError) Merge(path string, err error) {
+	var verr *ValidationError
+	switch {
+	case err == nil:
+	case errors.As(err, &verr):
+		for _, f := range verr.Fields {
+			e.Add(join(path, f.Field), f.Message)
+		}
+	default:
+		e.Add(path, err.Error())
+	}
+}
+
+// Err returns e when it lists invalid fields, and nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// join returns the path of field inside path.
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field, message string) {
+	v.fields = append(v.fields, FieldError{field, message})
+}
+
+func (v *validator) required(field, value string) bool {
+	if value == "" {
+		v.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// maxExponentCurrency has the most decimals of any currency, the only check
+// possible on amounts of an unknown currency.
+const maxExponentCurrency money.Currency = "CLF"
+
+// amount checks that value is a positive decimal amount with no more decimals
+// than currency c allows.
+func (v *validator) amount(field string, value money.Decimal, c money.Currency) {
+	if !v.required(field, string(value)) {
+		return
+	}
+	if c == "" {
+		c = maxExponentCurrency
+	}
+	m, err := money.Parse(string(value), c)
+	if err != nil || m.Sign() <= 0 {
+		v.add(field, "must be a positive amount with at most "+strconv.Itoa(c.Exponent())+" decimals")
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{v.fields}
+}
+
+// now is replaced by tests.
+var now = time.Now
+
+// Validate checks r before it is sent as a Sale or Auth: required fields,
+// amount and currency, a single payment source among creditCard, pfToken,
+// vaultedShopperId and wallet, card number, expiry and security code, and
+// country and state codes. It returns a *ValidationError listing every
+// invalid field.
+func (r Request) Validate() error {
+	v := &validator{}
+
+	switch {
+	case r.CardTransactionType == "":
+		v.add("cardTransactionType", "is required")
+	case r.CardTransactionType != TransactionAuthOnly && r.CardTransactionType != TransactionAuthCapture:
+		v.add("cardTransactionType", "must be AUTH_ONLY or AUTH_CAPTURE, use the dedicated requests for other types")
+	}
+
+	if v.required("currency", r.Currency) && !money.Currency(r.Currency).Valid() {
+		v.add("currency", "unknown ISO 4217 currency code "+strconv.Quote(r.Currency))
+	}
+	if v.required("amount", string(r.Amount)) {
+		if m, err := r.Money(); err != nil {
+			v.add("amount", err.Error())
+		} else if m.Sign() <= 0 {
+			v.add("amount", "must be positive")
+		}
+	}
+
+	var sources []string
+	if r.CreditCard != nil && (r.CreditCard.CardNumber != "" || r.CreditCard.EncryptedCardNumber != "") {
+		sources = append(sources, "creditCard")
+	}
+	if r.PFToken != "" {
+		sources = append(sources, "pfToken")
+	}
+	if r.VaultedShopperID != 0 {
+		sources = append(sources, "vaultedShopperId")
+	}
+	if r.Wallet != nil || r.WalletID != 0 {
+		sources = append(sources, "wallet")
+	}
+	switch len(sources) {
+	case 0:
+		v.add("creditCard", "one of creditCard, pfToken, vaultedShopperId or wallet is required")
+	case 1:
+	default:
+		for _, s := range sources[1:] {
+			v.add(s, "cannot be combined with "+sources[0])
+		}
+	}
+
+	if r.CreditCard != nil {
+		r.CreditCard.validate(v, "creditCard")
+	}
+	if r.CardHolderInfo != nil {
+		validateAddress(v, "cardHolderInfo", r.CardHolderInfo.Country, r.CardHolderInfo.State)
+	}
+	if r.TransactionFraudInfo != nil && r.TransactionFraudInfo.ShippingContactInfo != nil {
+		s := r.TransactionFraudInfo.ShippingContactInfo
+		validateAddress(v, "transactionFraudInfo.shippingContactInfo", s.Country, s.State)
+	}
+	if r.TransactionInitiator != "" && !r.TransactionInitiator.Valid() {
+		v.add("transactionInitiator", "must be SHOPPER or MERCHANT")
+	}
+	if r.TransactionOrderSource != "" && !r.TransactionOrderSource.Valid() {
+		v.add("transactionOrderSource", "must be ECOMMERCE or MOTO")
+	}
+	if r.RecurringTransaction != "" && !r.RecurringTransaction.Valid() {
+		v.add("recurringTransaction", "must be ECOMMERCE or RECURRING")
+	}
+	if r.NetworkTransactionInfo != nil {
+		switch {
+		case r.NetworkTransactionInfo.OriginalNetworkTransactionID == "":
+			v.add("networkTransactionInfo.originalNetworkTransactionId", "is required")
+		case r.TransactionInitiator != InitiatorMerchant:
+			v.add("networkTransactionInfo", "requires transactionInitiator MERCHANT")
+		}
+	}
+
+	return v.err()
+}
+
+// matchesBrand reports whether t is one of the brands of pan, co-badged
+// cards being accepted under the brand of their international network too.
+func matchesBrand(pan string, t CardType) bool {
+	for _, b := range bin.Brands(pan) {
+		if CardType(b) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the raw card fields of c as Request.Validate does, field
+// paths being relative to c, e.g. "cardNumber".
+func (c CreditCardRequest) Validate() error {
+	v := &validator{}
+	c.validate(v, "")
+	return v.err()
+}
+
+// validate checks the raw card fields of c, encrypted ones being opaque.
+func (c CreditCardRequest) validate(v *validator, path string) {
+	if c.CardType != "" && !c.CardType.Valid() {
+		v.add(join(path, "cardType"), "unknown card type "+strconv.Quote(string(c.CardType)))
+	}
+	if c.CardNumber != "" {
+		brand := bin.Detect(c.CardNumber)
+		switch {
+		case !digits(c.CardNumber):
+			v.add(join(path, "cardNumber"), "must only contain digits")
+		case !brand.ValidLength(len(c.CardNumber)):
+			v.add(join(path, "cardNumber"), "invalid length "+strconv.Itoa(len(c.CardNumber))+" for "+brandName(brand))
+		case !bin.Luhn(c.CardNumber):
+			v.add(join(path, "cardNumber"), "fails the Luhn check")
+		case c.CardType != "" && brand != bin.Unknown && !matchesBrand(c.CardNumber, c.CardType):
+			v.add(join(path, "cardType"), "does not match the card number brand "+string(brand))
+		}
+	}
+
+	if c.CardNumber != "" || c.EncryptedCardNumber != "" || c.ExpirationMonth != "" || c.ExpirationYear != "" {
+		month, year := 0, 0
+		if v.required(join(path, "expirationMonth"), c.ExpirationMonth) {
+			if m, err := strconv.Atoi(c.ExpirationMonth); err != nil || m < 1 || m > 12 {
+				v.add(join(path, "expirationMonth"), "must be 1 to 12")
+			} else {
+				month = m
+			}
+		}
+		if v.required(join(path, "expirationYear"), c.ExpirationYear) {
+			if y, err := strconv.Atoi(c.ExpirationYear); err != nil || y < 0 || len(c.ExpirationYear) != 2 && len(c.ExpirationYear) != 4 {
+				v.add(join(path, "expirationYear"), "must be 2 or 4 digits")
+			} else if year = y; y < 100 {
+				year += 2000
+			}
+		}
+		t := now()
+		if month > 0 && year > 0 && (year < t.Year() || year == t.Year() && month < int(t.Month())) {
+			v.add(join(path, "expirationYear"), "card has expired")
+		}
+	}
+
+	if c.SecurityCode != "" {
+		n := cvvLength(c.CardType, c.CardNumber)
+		if !digits(c.SecurityCode) || len(c.SecurityCode) != n {
+			v.add(join(path, "securityCode"), "must be "+strconv.Itoa(n)+" digits")
+		}
+	}
+}
+
+// Validate checks the country and state codes of b.
+func (b BillingContactInfo) Validate() error {
+	v := &validator{}
+	validateAddress(v, "", b.Country, b.State)
+	return v.err()
+}
+
+// Validate checks the country and state codes of s.
+func (s ShippingContactInfo) Validate() error {
+	v := &validator{}
+	validateAddress(v, "", s.Country, s.State)
+	return v.err()
+}
+
+func validateAddress(v *validator, path, country, state string) {
+	if country != "" && !validCountry(country) {
+		v.add(join(path, "country"), "unknown ISO 3166 country code "+strconv.Quote(country))
+		return
+	}
+	switch {
+	case state == "" && country != "" && requiresState(country):
+		v.add(join(path, "state"), "is required in "+strings.ToUpper(country))
+	case state != "" && !validState(country, state):
+		v.add(join(path, "state"), "unknown state code "+strconv.Quote(state))
+	}
+}
+
+// cvvLength returns the security code length of a card, from its type or
+// else its number.
+func cvvLength(t CardType, number string) int {
+	if t != "" {
+		return bin.Brand(t).CVVLength()
+	}
+	return bin.Detect(number).CVVLength()
+}
+
+func brandName(b bin.Brand) string {
+	if b == bin.Unknown {
+		return "an unknown brand"
+	}
+	return string(b)
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Validate checks that r names the transaction to capture and, when set, a
+// positive amount in r.Currency.
+func (r CaptureRequest) Validate() error {
+	v := &validator{}
+	v.required("transactionId", r.TransactionID)
+	if r.Amount != "" {
+		v.amount("amount", r.Amount, r.Currency)
+	}
+	return v.err()
+}
+
+// Validate checks that r names the authorization and its new amount, in
+// r.Currency.
+func (r UpdateAuthRequest) Validate() error {
+	v := &validator{}
+	v.required("transactionId", r.TransactionID)
+	v.amount("amount", r.Amount, r.Currency)
+	return v.err()
+}
+
+// Validate checks that r names the authorization to reverse.
+func (r ReversalRequest) Validate() error {
+	v := &validator{}
+	v.required("transactionId", r.TransactionID)
+	return v.err()
+}
+
+// Validate checks the amounts of r in r.Currency, a tax amount requiring an
+// amount.
+func (r RefundRequest) Validate() error {
+	v := &validator{}
+	if r.Amount != "" {
+		v.amount("amount", r.Amount, r.Currency)
+	}
+	if r.TaxAmount != "" {
+		v.amount("taxAmount", r.TaxAmount, r.Currency)
+		if r.Amount == "" {
+			v.add("taxAmount", "requires amount")
+		}
+	}
+	if r.VendorsRefundInfo != nil {
+		for i, vi := range r.VendorsRefundInfo.VendorRefundInfo {
+			v.amount("vendorsRefundInfo.vendorRefundInfo["+strconv.Itoa(i)+"].vendorAmount", vi.VendorAmount, r.Currency)
+		}
+	}
+	return v.err()
+}
@@ -0,0 +1,173 @@
+package card
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func validRequest() Request {
+	return Request{
+		CardTransactionType: TransactionAuthCapture,
+		Amount:              "11.00",
+		Currency:            "USD",
+		CreditCard: &CreditCardRequest{
+			CardNumber:      "4263982640269299",
+			ExpirationMonth: "03",
+			ExpirationYear:  "2030",
+			SecurityCode:    "837",
+		},
+		CardHolderInfo: &CardHolderInfo{Country: "us", State: "NY"},
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	now = func() time.Time { return time.Date(2030, time.March, 15, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name   string
+		modify func(r *Request)
+		fields []string
+	}{
+		{"valid until the end of the month", func(r *Request) { r.CreditCard.ExpirationMonth = "3" }, nil},
+		{"vaulted shopper selecting a stored card", func(r *Request) {
+			r.CreditCard = &CreditCardRequest{CardLastFourDigits: "9299", CardType: CardVisa}
+			r.VaultedShopperID = 42
+		}, nil},
+		{"missing fields", func(r *Request) {
+			r.CardTransactionType, r.Amount, r.Currency = "", "", ""
+		}, []string{"amount", "cardTransactionType", "currency"}},
+		{"capture type", func(r *Request) { r.CardTransactionType = TransactionCapture }, []string{"cardTransactionType"}},
+		{"amount format", func(r *Request) { r.Amount = "11.001" }, []string{"amount"}},
+		{"negative amount", func(r *Request) { r.Amount = "-1" }, []string{"amount"}},
+		{"yen decimals", func(r *Request) { r.Amount, r.Currency = "11.50", "JPY" }, []string{"amount"}},
+		{"yen", func(r *Request) { r.Amount, r.Currency = "1100", "JPY" }, nil},
+		{"dinar decimals", func(r *Request) { r.Amount, r.Currency = "1.005", "KWD" }, nil},
+		{"dollar with dinar decimals", func(r *Request) { r.Amount = "1.005" }, []string{"amount"}},
+		{"unknown currency", func(r *Request) { r.Currency = "ABC" }, []string{"currency"}},
+		{"no payment source", func(r *Request) { r.CreditCard = nil }, []string{"creditCard"}},
+		{"several payment sources", func(r *Request) {
+			r.PFToken = "abc"
+			r.Wallet = &WalletRequest{WalletType: "APPLE_PAY"}
+		}, []string{"pfToken", "wallet"}},
+		{"luhn", func(r *Request) { r.CreditCard.CardNumber = "4263982640269290" }, []string{"creditCard.cardNumber"}},
+		{"length", func(r *Request) { r.CreditCard.CardNumber = "42424242" }, []string{"creditCard.cardNumber"}},
+		{"brand length", func(r *Request) { r.CreditCard.CardNumber = "378282246310005" + "0" }, []string{"creditCard.cardNumber", "creditCard.securityCode"}},
+		{"type mismatch", func(r *Request) { r.CreditCard.CardType = CardMastercard }, []string{"creditCard.cardType"}},
+		{"co-badged network brand", func(r *Request) { r.CreditCard.CardNumber = "4970101122334422" }, nil},
+		{"co-badged local brand", func(r *Request) {
+			r.CreditCard.CardNumber = "4970101122334422"
+			r.CreditCard.CardType = CardCartesBancaires
+		}, nil},
+		{"co-badged mismatch", func(r *Request) {
+			r.CreditCard.CardNumber = "4970101122334422"
+			r.CreditCard.CardType = CardMastercard
+		}, []string{"creditCard.cardType"}},
+		{"non digits", func(r *Request) { r.CreditCard.CardNumber = "4263 9826 4026 9299" }, []string{"creditCard.cardNumber"}},
+		{"expired", func(r *Request) { r.CreditCard.ExpirationYear = "29" }, []string{"creditCard.expirationYear"}},
+		{"bad month", func(r *Request) { r.CreditCard.ExpirationMonth = "13" }, []string{"creditCard.expirationMonth"}},
+		{"missing expiry", func(r *Request) { r.CreditCard.ExpirationYear = "" }, []string{"creditCard.expirationYear"}},
+		{"amex cvv", func(r *Request) {
+			r.CreditCard.CardNumber = "378282246310005"
+		}, []string{"creditCard.securityCode"}},
+		{"amex cvv ok", func(r *Request) {
+			r.CreditCard.CardNumber = "378282246310005"
+			r.CreditCard.SecurityCode = "1234"
+		}, nil},
+		{"country", func(r *Request) { r.CardHolderInfo.Country = "XX" }, []string{"cardHolderInfo.country"}},
+		{"state", func(r *Request) { r.CardHolderInfo.State = "ZZ" }, []string{"cardHolderInfo.state"}},
+		{"missing state", func(r *Request) { r.CardHolderInfo.State = "" }, []string{"cardHolderInfo.state"}},
+		{"state free country", func(r *Request) { r.CardHolderInfo = &CardHolderInfo{Country: "fr", State: "IDF"} }, nil},
+		{"initiator", func(r *Request) { r.TransactionInitiator = "SYSTEM" }, []string{"transactionInitiator"}},
+		{"network transaction of a shopper", func(r *Request) {
+			r.NetworkTransactionInfo = &NetworkTransactionInfo{OriginalNetworkTransactionID: "019072416113666"}
+		}, []string{"networkTransactionInfo"}},
+		{"network transaction without ID", func(r *Request) {
+			r.TransactionInitiator = InitiatorMerchant
+			r.NetworkTransactionInfo = &NetworkTransactionInfo{}
+		}, []string{"networkTransactionInfo.originalNetworkTransactionId"}},
+	}
+
+	for _, test := range tests {
+		r := validRequest()
+		test.modify(&r)
+		err := r.Validate()
+		var fields []string
+		if err != nil {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("%s: expected a *ValidationError, got %T", test.name, err)
+			}
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+			sort.Strings(fields)
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("%s: expected invalid fields %v, got %v (%v)", test.name, test.fields, fields, err)
+		}
+	}
+}
+
+func TestValidateOtherRequests(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{ Validate() error }
+		valid bool
+	}{
+		{"capture", CaptureRequest{TransactionID: "1"}, true},
+		{"partial capture", CaptureRequest{TransactionID: "1", Amount: "5.5"}, true},
+		{"capture without transaction", CaptureRequest{Amount: "5"}, false},
+		{"capture with bad amount", CaptureRequest{TransactionID: "1", Amount: "five"}, false},
+		{"capture in dollars", CaptureRequest{TransactionID: "1", Amount: "1.005", Currency: "USD"}, false},
+		{"capture in yens", CaptureRequest{TransactionID: "1", Amount: "5.5", Currency: "JPY"}, false},
+		{"capture of unknown currency", CaptureRequest{TransactionID: "1", Amount: "1.005"}, true},
+		{"update auth", UpdateAuthRequest{TransactionID: "1", Amount: "12"}, true},
+		{"update auth in dollars", UpdateAuthRequest{TransactionID: "1", Amount: "12.001", Currency: "USD"}, false},
+		{"update auth without amount", UpdateAuthRequest{TransactionID: "1"}, false},
+		{"reversal", ReversalRequest{TransactionID: "1"}, true},
+		{"reversal without transaction", ReversalRequest{}, false},
+		{"full refund", RefundRequest{}, true},
+		{"refund with tax", RefundRequest{Amount: "10", TaxAmount: "1"}, true},
+		{"tax without amount", RefundRequest{TaxAmount: "1"}, false},
+		{"refund in dollars", RefundRequest{Amount: "10", TaxAmount: "0.005", Currency: "USD"}, false},
+		{"vendor refund", RefundRequest{VendorsRefundInfo: &VendorsRefundInfo{VendorRefundInfo: []VendorRefundInfo{{VendorID: 1}}}}, false},
+		{"card", CreditCardRequest{CardNumber: "4263982640269299", ExpirationMonth: "03", ExpirationYear: "2099"}, true},
+		{"card failing Luhn", CreditCardRequest{CardNumber: "4263982640269298", ExpirationMonth: "03", ExpirationYear: "2099"}, false},
+		{"card to remove", CreditCardRequest{CardLastFourDigits: "9299", CardType: CardVisa}, true},
+		{"billing contact", BillingContactInfo{Country: "us", State: "NY"}, true},
+		{"billing contact without state", BillingContactInfo{Country: "us"}, false},
+		{"shipping contact of unknown country", ShippingContactInfo{Country: "zz"}, false},
+	}
+	for _, test := range tests {
+		if err := test.input.Validate(); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestValidationErrorMerge(t *testing.T) {
+	e := &ValidationError{}
+	if e.Err() != nil {
+		t.Fatal("an empty ValidationError should not be an error")
+	}
+	e.Merge("", BillingContactInfo{Country: "us"}.Validate())
+	e.Merge("creditCard", CreditCardRequest{CardNumber: "42", ExpirationMonth: "13", ExpirationYear: "2099"}.Validate())
+	e.Merge("pfToken", errors.New("expired"))
+	e.Merge("wallet", nil)
+
+	var fields []string
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field)
+	}
+	expected := []string{"state", "creditCard.cardNumber", "creditCard.expirationMonth", "pfToken"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected fields %v, got %v", expected, fields)
+	}
+	if err := e.Err(); err != e {
+		t.Errorf("expected Err to return e, got %v", err)
+	}
+}
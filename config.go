@@ -0,0 +1,399 @@
+package bluesnap
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config describes a Connector, see NewFromConfig. It can be loaded with
+// LoadConfig from a JSON or YAML file or with ConfigFromEnv from environment
+// variables.
+type Config struct {
+	// Environment accepts "sandbox", "production" or a URL when decoded.
+	Environment Environment `json:"environment"`
+	Credentials Credentials `json:"credentials"`
+	// Account declares the kind of account Credentials belong to, see
+	// Validate.
+	Account Account `json:"account"`
+	// Timeout bounds every HTTP call, no timeout when zero.
+	Timeout    Duration    `json:"timeout"`
+	Retry      RetryConfig `json:"retry"`
+	LogLevel   LogLevel    `json:"logLevel"`
+	APIVersion string      `json:"apiVersion"`
+	// ValidateRequests sets Connector.ValidateRequests.
+	ValidateRequests bool `json:"validateRequests"`
+	// Logger receives the logs of calls when LogLevel is not off, a standard
+	// library logger writing to stderr when nil.
+	Logger Logger `json:"-"`
+}
+
+// Account is the kind of BlueSnap account, sandbox or production, that
+// credentials were issued for.
+type Account string
+
+const (
+	AccountSandbox    Account = "sandbox"
+	AccountProduction Account = "production"
+)
+
+// RetryConfig is the serializable form of RetryPolicy.
+type RetryConfig struct {
+	MaxAttempts    int      `json:"maxAttempts"`
+	InitialBackoff Duration `json:"initialBackoff"`
+	MaxBackoff     Duration `json:"maxBackoff"`
+	Multiplier     float64  `json:"multiplier"`
+	Jitter         float64  `json:"jitter"`
+}
+
+func (r RetryConfig) Policy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    r.MaxAttempts,
+		InitialBackoff: r.InitialBackoff.Duration,
+		MaxBackoff:     r.MaxBackoff.Duration,
+		Multiplier:     r.Multiplier,
+		Jitter:         r.Jitter,
+	}
+}
+
+// Duration is a time.Duration read from strings such as "30s" or "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Validate checks that c describes a usable Connector. When c.Account is
+// set, it refuses sandbox credentials with the Production environment and
+// production credentials with the Sandbox one. Custom environment URLs, and
+// configs without Account, are not checked against the credentials.
+func (c Config) Validate() error {
+	var errs []string
+	if c.Environment == "" {
+		errs = append(errs, "environment is required")
+	} else if _, err := ParseEnvironment(string(c.Environment)); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if c.Credentials.Username == "" || c.Credentials.Password == "" {
+		errs = append(errs, "credentials username and password are required")
+	}
+	switch {
+	case c.Account != "" && c.Account != AccountSandbox && c.Account != AccountProduction:
+		errs = append(errs, fmt.Sprintf("unknown account %q, expected sandbox or production", c.Account))
+	case c.Account == AccountSandbox && c.Environment.IsProduction():
+		errs = append(errs, "sandbox credentials cannot be used with the production environment")
+	case c.Account == AccountProduction && c.Environment == Sandbox:
+		errs = append(errs, "production credentials cannot be used with the sandbox environment")
+	}
+	if c.Timeout.Duration < 0 {
+		errs = append(errs, "timeout cannot be negative")
+	}
+	if c.Retry.MaxAttempts < 0 || c.Retry.InitialBackoff.Duration < 0 || c.Retry.MaxBackoff.Duration < 0 || c.Retry.Jitter < 0 || c.Retry.Jitter > 1 {
+		errs = append(errs, "retry values are out of range")
+	}
+	if c.LogLevel != "" && !c.LogLevel.Valid() {
+		errs = append(errs, fmt.Sprintf("unknown log level %q", c.LogLevel))
+	}
+
+	if len(errs) > 0 {
+		return errors.New("bluesnap: invalid config: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// NewFromConfig validates cfg and creates a Connector from it.
+func NewFromConfig(cfg Config) (Connector, error) {
+	if err := cfg.Validate(); err != nil {
+		return Connector{}, err
+	}
+
+	c := New(&http.Client{Timeout: cfg.Timeout.Duration}, cfg.Environment.URL())
+	c.Credentials = cfg.Credentials
+	c.Retry = cfg.Retry.Policy()
+	c.APIVersion = cfg.APIVersion
+	c.ValidateRequests = cfg.ValidateRequests
+
+	if cfg.LogLevel != "" && cfg.LogLevel != LogOff {
+		logger := cfg.Logger
+		if logger == nil {
+			logger = stdLogger{log.New(os.Stderr, "bluesnap ", log.LstdFlags)}
+		}
+		c.Middleware = append(c.Middleware, LoggingMiddleware(LevelLogger(logger, cfg.LogLevel), RedactionPolicy{}))
+	}
+	return c, nil
+}
+
+// LoadConfig reads a Config from a JSON file, or a YAML one when path ends in
+// .yaml or .yml. Only the YAML subset needed by Config is supported: nested
+// mappings of scalars, indented with spaces, and comments.
+func LoadConfig(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		doc, err := parseYAML(data)
+		if err != nil {
+			return Config{}, fmt.Errorf("bluesnap: reading config from %s: %w", path, err)
+		}
+		typed, err := yamlValue(doc, reflect.TypeOf(Config{}), "")
+		if err != nil {
+			return Config{}, fmt.Errorf("bluesnap: reading config from %s: %w", path, err)
+		}
+		if data, err = json.Marshal(typed); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("bluesnap: reading config from %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ConfigFromEnv reads a Config from environment variables named <prefix>_
+// followed by ENVIRONMENT, USERNAME, PASSWORD, ACCOUNT, TIMEOUT,
+// RETRY_MAX_ATTEMPTS, RETRY_INITIAL_BACKOFF, RETRY_MAX_BACKOFF,
+// RETRY_MULTIPLIER, RETRY_JITTER, LOG_LEVEL, API_VERSION and
+// VALIDATE_REQUESTS. Prefix defaults to BLUESNAP.
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = "BLUESNAP"
+	}
+	get := func(key string) string {
+		return os.Getenv(prefix + "_" + key)
+	}
+
+	cfg := Config{
+		Credentials: Credentials{
+			Username: get("USERNAME"),
+			Password: get("PASSWORD"),
+		},
+		Account:    Account(strings.ToLower(get("ACCOUNT"))),
+		LogLevel:   LogLevel(strings.ToLower(get("LOG_LEVEL"))),
+		APIVersion: get("API_VERSION"),
+	}
+
+	var errs []string
+	parse := func(key string, fn func(string) error) {
+		if v := get(key); v != "" {
+			if err := fn(v); err != nil {
+				errs = append(errs, prefix+"_"+key+": "+err.Error())
+			}
+		}
+	}
+	parse("ENVIRONMENT", func(v string) error { return cfg.Environment.UnmarshalText([]byte(v)) })
+	parse("TIMEOUT", func(v string) error { return cfg.Timeout.UnmarshalText([]byte(v)) })
+	parse("RETRY_MAX_ATTEMPTS", func(v string) (err error) {
+		cfg.Retry.MaxAttempts, err = strconv.Atoi(v)
+		return err
+	})
+	parse("RETRY_INITIAL_BACKOFF", func(v string) error { return cfg.Retry.InitialBackoff.UnmarshalText([]byte(v)) })
+	parse("RETRY_MAX_BACKOFF", func(v string) error { return cfg.Retry.MaxBackoff.UnmarshalText([]byte(v)) })
+	parse("RETRY_MULTIPLIER", func(v string) (err error) {
+		cfg.Retry.Multiplier, err = strconv.ParseFloat(v, 64)
+		return err
+	})
+	parse("RETRY_JITTER", func(v string) (err error) {
+		cfg.Retry.Jitter, err = strconv.ParseFloat(v, 64)
+		return err
+	})
+	parse("VALIDATE_REQUESTS", func(v string) (err error) {
+		cfg.ValidateRequests, err = strconv.ParseBool(v)
+		return err
+	})
+
+	if len(errs) > 0 {
+		return cfg, errors.New("bluesnap: invalid environment variables: " + strings.Join(errs, "; "))
+	}
+	return cfg, nil
+}
+
+// parseYAML parses nested mappings of scalars into maps of strings, the
+// target type deciding later how to read each scalar, see yamlValue.
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	type level struct {
+		indent int
+		m      map[string]interface{}
+	}
+	root := map[string]interface{}{}
+	stack := []level{{-1, root}}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = stripYAMLComment(line)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		colon := strings.Index(trimmed, ":")
+		if colon <= 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", i+1)
+		}
+		key := unquoteYAML(strings.TrimSpace(trimmed[:colon]))
+		value := strings.TrimSpace(trimmed[colon+1:])
+
+		for indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].m
+		if value == "" {
+			child := map[string]interface{}{}
+			parent[key] = child
+			stack = append(stack, level{indent, child})
+			continue
+		}
+		if quoted := value[0] == '"' || value[0] == '\''; quoted && (len(value) < 2 || value[len(value)-1] != value[0]) {
+			return nil, fmt.Errorf("line %d: unterminated quoted value", i+1)
+		}
+		parent[key] = yamlScalar(value)
+	}
+	return root, nil
+}
+
+// stripYAMLComment removes the comment ending line, a "#" at its start or
+// after a space, outside of quoted scalars.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// yamlScalar returns the string held by v, or nil for null.
+func yamlScalar(v string) interface{} {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		return unquoteYAML(v)
+	}
+	if v == "null" || v == "~" {
+		return nil
+	}
+	return v
+}
+
+// yamlValue converts the strings of a parsed YAML document into the JSON
+// values expected by the fields of t: numbers and booleans for fields of
+// those kinds, strings otherwise, so that e.g. a password of 123456 stays a
+// string.
+func yamlValue(v interface{}, t reflect.Type, path string) (interface{}, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			var ct reflect.Type
+			if t != nil {
+				switch t.Kind() {
+				case reflect.Struct:
+					if f, ok := jsonField(t, key); ok {
+						ct = f.Type
+					}
+				case reflect.Map:
+					ct = t.Elem()
+				}
+			}
+			value, err := yamlValue(child, ct, strings.TrimPrefix(path+"."+key, "."))
+			if err != nil {
+				return nil, err
+			}
+			out[key] = value
+		}
+		return out, nil
+	case string:
+		if t == nil || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+			return v, nil
+		}
+		switch t.Kind() {
+		case reflect.Bool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid boolean %q", path, v)
+			}
+			return b, nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("%s: invalid number %q", path, v)
+			}
+			return json.Number(v), nil
+		}
+	}
+	return v, nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// jsonField returns the field of struct t that encoding/json decodes key
+// into.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func unquoteYAML(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+	}
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		return strings.Replace(v[1:len(v)-1], "''", "'", -1)
+	}
+	return v
+}
@@ -0,0 +1,260 @@
+package bluesnap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	tests := []struct {
+		in         string
+		want       Environment
+		production bool
+		wantErr    bool
+	}{
+		{"sandbox", Sandbox, false, false},
+		{"Production", Production, true, false},
+		{"http://localhost:8080/", Environment("http://localhost:8080"), false, false},
+		{"localhost", "", false, true},
+	}
+	for _, test := range tests {
+		env, err := ParseEnvironment(test.in)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: unexpected error %v", test.in, err)
+		}
+		if env != test.want {
+			t.Errorf("%s: environment should be %s, instead of %s", test.in, test.want, env)
+		}
+		if env.IsProduction() != test.production {
+			t.Errorf("%s: production should be %v", test.in, test.production)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bluesnap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.json": `{
+			"environment": "production",
+			"credentials": {"username": "API_123", "password": "secret"},
+			"timeout": "30s",
+			"retry": {"maxAttempts": 3, "initialBackoff": "200ms", "maxBackoff": "5s", "multiplier": 2, "jitter": 0.2},
+			"logLevel": "info",
+			"apiVersion": "3.0"
+		}`,
+		"config.yaml": `# BlueSnap
+environment: production
+credentials:
+  username: API_123
+  password: "secret"
+timeout: 30s
+retry:
+  maxAttempts: 3 # total
+  initialBackoff: 200ms
+  maxBackoff: 5s
+  multiplier: 2
+  jitter: 0.2
+logLevel: info
+apiVersion: 3.0
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Environment != Production ||
+				cfg.Credentials != (Credentials{Username: "API_123", Password: "secret"}) ||
+				cfg.Timeout.Duration != 30*time.Second ||
+				cfg.Retry.Policy() != (RetryPolicy{MaxAttempts: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second, Multiplier: 2, Jitter: 0.2}) ||
+				cfg.LogLevel != LogInfo ||
+				cfg.APIVersion != "3.0" {
+				t.Errorf("unexpected config %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadConfigYAMLScalars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bluesnap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+		check   func(cfg Config) bool
+		wantErr string
+	}{
+		{"hash in double quotes", `credentials:
+  password: "abc #def" # rotated monthly
+`, func(cfg Config) bool { return cfg.Credentials.Password == "abc #def" }, ""},
+		{"hash in single quotes", `credentials:
+  password: 'it''s #1'
+`, func(cfg Config) bool { return cfg.Credentials.Password == "it's #1" }, ""},
+		{"hash without space", `credentials:
+  password: abc#def
+`, func(cfg Config) bool { return cfg.Credentials.Password == "abc#def" }, ""},
+		{"numeric password", `credentials:
+  username: 42
+  password: 123456
+`, func(cfg Config) bool { return cfg.Credentials == Credentials{Username: "42", Password: "123456"} }, ""},
+		{"boolean looking password", `credentials:
+  password: true
+validateRequests: true
+`, func(cfg Config) bool { return cfg.Credentials.Password == "true" && cfg.ValidateRequests }, ""},
+		{"invalid number", `retry:
+  maxAttempts: three
+`, nil, "retry.maxAttempts"},
+		{"invalid boolean", `validateRequests: sometimes
+`, nil, "validateRequests"},
+		{"unterminated quote", `credentials:
+  password: "abc
+`, nil, "line 2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("expected an error about %s, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.check(cfg) {
+				t.Errorf("unexpected config %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"TESTCFG_ENVIRONMENT":        "sandbox",
+		"TESTCFG_USERNAME":           "API_123",
+		"TESTCFG_PASSWORD":           "secret",
+		"TESTCFG_ACCOUNT":            "Sandbox",
+		"TESTCFG_TIMEOUT":            "10s",
+		"TESTCFG_RETRY_MAX_ATTEMPTS": "4",
+		"TESTCFG_LOG_LEVEL":          "DEBUG",
+		"TESTCFG_VALIDATE_REQUESTS":  "true",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	cfg, err := ConfigFromEnv("TESTCFG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != Sandbox || cfg.Credentials.Username != "API_123" || cfg.Timeout.Duration != 10*time.Second || cfg.Retry.MaxAttempts != 4 || cfg.LogLevel != LogDebug || !cfg.ValidateRequests || cfg.Account != AccountSandbox {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	os.Setenv("TESTCFG_TIMEOUT", "soon")
+	if _, err := ConfigFromEnv("TESTCFG"); err == nil || !strings.Contains(err.Error(), "TESTCFG_TIMEOUT") {
+		t.Errorf("expected an invalid timeout error, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Environment: Sandbox, Credentials: Credentials{Username: "API_123", Password: "secret"}}
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"missing environment", func(c *Config) { c.Environment = "" }, "environment is required"},
+		{"missing credentials", func(c *Config) { c.Credentials = Credentials{} }, "credentials"},
+		{"sandbox credentials in production", func(c *Config) {
+			c.Environment = Production
+			c.Account = AccountSandbox
+		}, "sandbox credentials"},
+		{"production credentials in sandbox", func(c *Config) { c.Account = AccountProduction }, "production credentials"},
+		{"sandbox credentials in sandbox", func(c *Config) { c.Account = AccountSandbox }, ""},
+		{"production credentials in production", func(c *Config) {
+			c.Environment = Production
+			c.Account = AccountProduction
+		}, ""},
+		{"production credentials through a proxy", func(c *Config) {
+			c.Environment = "https://bluesnap-proxy.internal"
+			c.Account = AccountProduction
+		}, ""},
+		{"undeclared account in production", func(c *Config) {
+			c.Environment = Production
+			c.Credentials.Username = "API_test_team"
+		}, ""},
+		{"unknown account", func(c *Config) { c.Account = "staging" }, "unknown account"},
+		{"unknown log level", func(c *Config) { c.LogLevel = "verbose" }, "log level"},
+		{"negative timeout", func(c *Config) { c.Timeout.Duration = -time.Second }, "timeout"},
+	}
+	for _, test := range tests {
+		cfg := valid
+		test.modify(&cfg)
+		err := cfg.Validate()
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.wantErr, err)
+		}
+		if _, err := NewFromConfig(cfg); err == nil {
+			t.Errorf("%s: NewFromConfig should refuse invalid configs", test.name)
+		}
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	logger := &testLogger{}
+	c, err := NewFromConfig(Config{
+		Environment: Sandbox,
+		Credentials: Credentials{Username: "API_123", Password: "secret"},
+		Timeout:     Duration{10 * time.Second},
+		Retry:       RetryConfig{MaxAttempts: 2},
+		LogLevel:    LogInfo,
+		APIVersion:  "3.0",
+		Logger:      logger,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.url != Sandbox.URL() || c.Client.Timeout != 10*time.Second || c.Retry.MaxAttempts != 2 || c.APIVersion != "3.0" || c.Credentials.Username != "API_123" {
+		t.Errorf("unexpected connector %+v", c)
+	}
+	if len(c.Middleware) != 1 {
+		t.Errorf("expected the logging middleware to be installed")
+	}
+}
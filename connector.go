@@ -2,9 +2,10 @@ package bluesnap
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"reflect"
@@ -20,18 +21,55 @@ type Deserializer interface {
 	Method() string
 }
 
+// HeaderDeserializer is implemented by outputs that read response headers,
+// e.g. the Location of a created resource. FromHeader is called after
+// FromJSON.
+type HeaderDeserializer interface {
+	FromHeader(h http.Header) error
+}
+
+// Validator is implemented by inputs that can check themselves before being
+// sent, see Connector.ValidateRequests.
+type Validator interface {
+	Validate() error
+}
+
 type Connector struct {
 	Client *http.Client
-	url    string
+	// Credentials are used by calls that don't set Opts.Credentials and
+	// aren't resolved by CredentialsProvider.
+	Credentials         Credentials
+	CredentialsProvider CredentialsProvider
+	// Retry controls how failed calls are retried. The zero value makes a
+	// single attempt.
+	Retry RetryPolicy
+	// RecoverDuplicates makes calls failing ambiguously (timeouts, including
+	// an expired context deadline, connection resets, 5xx) look up the
+	// transaction by its Idempotent key and return it, marked as recovered,
+	// when it was created after all. Canceled calls are not looked up.
+	RecoverDuplicates bool
+	// Middleware wraps every call, the first element being the outermost.
+	Middleware []Middleware
+	// APIVersion is sent in the bluesnap-version header when set.
+	APIVersion string
+	// ValidateRequests makes calls whose input is a Validator fail without
+	// reaching BlueSnap when the input is invalid. The error matches
+	// ErrInvalidInput and wraps the one returned by Validate.
+	ValidateRequests bool
+	url              string
 }
 
 type Opts struct {
+	// Credentials override the credentials of the Connector for one call.
 	Credentials Credentials
+	// Merchant selects the account whose credentials the Connector's
+	// CredentialsProvider resolves.
+	Merchant string
 }
 
 type Credentials struct {
-	Username string
-	Password string
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 func New(client *http.Client, url string) Connector {
@@ -41,52 +79,120 @@ func New(client *http.Client, url string) Connector {
 	}
 }
 
-func (c Connector) do(method, endpoint string, input Serializer, output Deserializer, opts Opts) (Errors, error) {
-	if reflect.ValueOf(output).Kind() != reflect.Ptr {
-		return emptyErrors(), errors.New("output must be a pointer")
+func (c Connector) do(ctx context.Context, op Operation, method, endpoint string, input Serializer, output Deserializer, opts Opts) error {
+	if output != nil && reflect.ValueOf(output).Kind() != reflect.Ptr {
+		return errors.New("output must be a pointer")
 	}
 
-	var buf *bytes.Buffer = nil
+	if v, ok := input.(Validator); ok && c.ValidateRequests {
+		if err := v.Validate(); err != nil {
+			return validationError{err}
+		}
+	}
+
+	creds, err := c.credentials(ctx, opts)
+	if err != nil {
+		return err
+	}
+	opts.Credentials = creds
+
+	req := &APIRequest{
+		Operation:  op,
+		HTTPMethod: method,
+		Endpoint:   endpoint,
+		Input:      input,
+		Output:     output,
+		Opts:       opts,
+		Header:     http.Header{},
+	}
 	if input != nil {
 		body, err := input.ToJSON()
 		if err != nil {
-			return emptyErrors(), err
+			return err
 		}
-		buf = bytes.NewBuffer(body)
+		req.Body = body
 	}
 
-	req, err := http.NewRequest(method, c.getURL(endpoint), buf)
+	resp, err := c.handler()(ctx, req)
 	if err != nil {
-		return emptyErrors(), err
+		return err
 	}
 
-	req.Header.Add("Authorization", "Basic "+opts.Credentials.Parse())
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Accept", "application/json")
+	if resp.StatusCode > 399 {
+		return newAPIError(resp.StatusCode, resp.Header, resp.Body)
+	}
 
-	resp, err := c.Client.Do(req)
+	if output != nil {
+		if err := output.FromJSON(resp.Body); err != nil {
+			return err
+		}
+		if h, ok := output.(HeaderDeserializer); ok {
+			if err := h.FromHeader(resp.Header); err != nil {
+				return err
+			}
+		}
+		if r, ok := output.(Recoverable); ok && resp.Recovered {
+			r.MarkRecovered()
+		}
+	}
+	return nil
+}
+
+// handler chains c.Middleware around the built-in retries and the HTTP call.
+func (c Connector) handler() Handler {
+	h := c.retry(c.send)
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		h = c.Middleware[i](h)
+	}
+	return h
+}
+
+// send performs req over HTTP, it is the innermost Handler.
+func (c Connector) send(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+	var buf io.Reader
+	if req.Body != nil {
+		buf = bytes.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.HTTPMethod, c.getURL(req.Endpoint), buf)
 	if err != nil {
-		return emptyErrors(), err
+		return nil, err
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+	httpReq.Header.Add("Authorization", "Basic "+req.Opts.Credentials.Parse())
+	httpReq.Header.Add("Content-Type", "application/json")
+	httpReq.Header.Add("Accept", "application/json")
+	if c.APIVersion != "" {
+		httpReq.Header.Add("bluesnap-version", c.APIVersion)
+	}
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+
+	resp, err := c.Client.Do(httpReq)
 	if err != nil {
-		return emptyErrors(), err
+		return nil, contextErr(ctx, err)
 	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode > 399 {
-		var errs Errors
-		if err := json.Unmarshal(respBody, &errs); err != nil {
-			return emptyErrors(), err
-		}
-		errs.StatusCode = resp.StatusCode
-		return errs, nil
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, contextErr(ctx, err)
 	}
 
-	if output != nil {
-		return emptyErrors(), output.FromJSON(respBody)
+	return &APIResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, nil
+}
+
+// contextErr replaces err with a canceledError when it was caused by ctx.
+func contextErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return canceledError{cause: ctx.Err()}
 	}
-	return emptyErrors(), nil
+	return err
 }
 
 func (c Connector) getURL(endpoint string) string {
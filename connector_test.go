@@ -1,6 +1,7 @@
 package bluesnap
 
 import (
+	"context"
 	"math/rand"
 	"net/http"
 	"reflect"
@@ -52,8 +53,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511869",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:08:47",
@@ -126,8 +127,8 @@ func TestCardAuth(t *testing.T) {
 				},
 				TransactionID:           "1035511531",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  100,
-				USDAmount:               100,
+				Amount:                  "100",
+				USDAmount:               "100",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:11:56",
@@ -209,8 +210,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511881",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:14:14",
@@ -285,8 +286,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511971",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:16:27",
@@ -344,8 +345,8 @@ func TestCardAuth(t *testing.T) {
 				MerchantTransactionId:   "31233",
 				TransactionID:           "1035511537",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:17:56",
@@ -395,8 +396,8 @@ func TestCardAuth(t *testing.T) {
 		//		PFToken:             "07ca959efe86d79f70919edc6b057e18b886096e72cb0a7f50707ff6c2e4a739_",
 		//	},
 		//	output: &card.Response{
-		//		Amount:                  11,
-		//		USDAmount:               11,
+		//		Amount:                  "11",
+		//		USDAmount:               "11",
 		//		Currency:                "USD",
 		//		TransactionApprovalDate: "09/29/2020",
 		//		TransactionApprovalTime: "13:16:27",
@@ -449,8 +450,8 @@ func TestCardAuth(t *testing.T) {
 				MerchantTransactionId:   "3",
 				TransactionID:           "1035511807",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -492,8 +493,8 @@ func TestCardAuth(t *testing.T) {
 		//		PFToken:             "abcde12345**********", // TODO missing valid PF token
 		//	},
 		//	output: &card.Response{
-		//		Amount:                  11,
-		//		USDAmount:               11,
+		//		Amount:                  "11",
+		//		USDAmount:               "11",
 		//		Currency:                "USD",
 		//		TransactionApprovalDate: "09/29/2020",
 		//		TransactionApprovalTime: "13:23:19",
@@ -561,8 +562,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511921",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -616,14 +617,14 @@ func TestCardAuth(t *testing.T) {
 							FraudProductDesc:     "my product",
 							FraudProductType:     "Online game",
 							FraudProductQuantity: 1,
-							FraudProductPrice:    14.5,
+							FraudProductPrice:    "14.5",
 						},
 						{
 							FraudProductName:     "345RRC",
 							FraudProductDesc:     "my product2",
 							FraudProductType:     "Video game",
 							FraudProductQuantity: 2,
-							FraudProductPrice:    18,
+							FraudProductPrice:    "18",
 						},
 					},
 					ShippingContactInfo: &card.ShippingContactInfo{
@@ -649,8 +650,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511921",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11.0,
-				USDAmount:               11.0,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -734,8 +735,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511921",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11.0,
-				USDAmount:               11.0,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -785,8 +786,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511921",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -825,8 +826,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035511921",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -871,8 +872,8 @@ func TestCardAuth(t *testing.T) {
 				CardTransactionType:     "AUTH_CAPTURE",
 				TransactionID:           "1035512361",
 				SoftDescriptor:          "BLS*DescTest",
-				Amount:                  11,
-				USDAmount:               11,
+				Amount:                  "11",
+				USDAmount:               "11",
 				Currency:                "USD",
 				TransactionApprovalDate: "09/29/2020",
 				TransactionApprovalTime: "13:23:19",
@@ -926,8 +927,8 @@ func TestCardAuth(t *testing.T) {
 		//		MerchantTransactionId:   "112233",
 		//		TransactionID:           "38602972",
 		//		SoftDescriptor:          "BLS*DescTest",
-		//		Amount:                  11,
-		//		USDAmount:               11,
+		//		Amount:                  "11",
+		//		USDAmount:               "11",
 		//		Currency:                "USD",
 		//		TransactionApprovalDate: "09/29/2020",
 		//		TransactionApprovalTime: "13:23:19",
@@ -991,8 +992,8 @@ func TestCardAuth(t *testing.T) {
 		//		CardTransactionType:     "AUTH_CAPTURE",
 		//		TransactionID:           "38602972",
 		//		SoftDescriptor:          "BLS&#x2a;DescTest",
-		//		Amount:                  11,
-		//		USDAmount:               11,
+		//		Amount:                  "11",
+		//		USDAmount:               "11",
 		//		Currency:                "USD",
 		//		TransactionApprovalDate: "09/29/2020",
 		//		TransactionApprovalTime: "13:23:19",
@@ -1042,8 +1043,8 @@ func TestCardAuth(t *testing.T) {
 		//		CardTransactionType:     "AUTH_CAPTURE",
 		//		TransactionID:           "38510976",
 		//		SoftDescriptor:          "BLS*DescTest",
-		//		Amount:                  10,
-		//		USDAmount:               10,
+		//		Amount:                  "10",
+		//		USDAmount:               "10",
 		//		Currency:                "USD",
 		//		TransactionApprovalDate: "09/29/2020",
 		//		TransactionApprovalTime: "13:23:19",
@@ -1082,9 +1083,8 @@ func TestCardAuth(t *testing.T) {
 		//		VaultedShopperID:     19574632,
 		//		CardTransactionType:  "AUTH_CAPTURE",
 		//		TransactionInitiator: "MERCHANT",
-		//		NetworkTransactionInfo: map[string]string{
-		//			"originalNetworkTransactionId": "019072416113666",
-		//			"networkTransactionId":         "019072416144666",
+		//		NetworkTransactionInfo: &card.NetworkTransactionInfo{
+		//			OriginalNetworkTransactionID: "019072416113666",
 		//		},
 		//	},
 		//},
@@ -1133,7 +1133,7 @@ func TestCardAuth(t *testing.T) {
 	}
 	for _, scenario := range scenarios {
 		resp := card.Response{}
-		if err := c.do("POST", "/services/2/transactions", scenario.input, &resp, opts); err != nil {
+		if err := c.do(context.Background(), OpSale, "POST", "/services/2/transactions", scenario.input, &resp, opts); err != nil {
 			t.Errorf(err.Error())
 		}
 		if scenario.output == nil {
@@ -1145,11 +1145,11 @@ func TestCardAuth(t *testing.T) {
 }
 
 func compareResponses(t *testing.T, expected, actual card.Response) {
-	equalsString(t, "cardTransactionType", expected.CardTransactionType, actual.CardTransactionType)
+	equalsString(t, "cardTransactionType", string(expected.CardTransactionType), string(actual.CardTransactionType))
 	nonEmptyString(t, "transactionId", actual.TransactionID)
 	equalsString(t, "softDescriptor", expected.SoftDescriptor, actual.SoftDescriptor)
-	equalsFloat64(t, "Amount", expected.Amount, actual.Amount)
-	equalsFloat64(t, "usdAmount", expected.USDAmount, actual.USDAmount)
+	equalsString(t, "Amount", string(expected.Amount), string(actual.Amount))
+	equalsString(t, "usdAmount", string(expected.USDAmount), string(actual.USDAmount))
 	equalsString(t, "currency", expected.Currency, actual.Currency)
 	nonEmptyString(t, "transactionApprovalDate", actual.TransactionApprovalDate)
 	nonEmptyString(t, "transactionApprovalTime", actual.TransactionApprovalTime)
@@ -1165,7 +1165,7 @@ func compareResponses(t *testing.T, expected, actual card.Response) {
 	compareTransactionMetadata(t, expected.TransactionMetadata, actual.TransactionMetadata)
 	equalsString(t, "merchantTransactionId", expected.MerchantTransactionId, actual.MerchantTransactionId)
 	equalsString(t, "taxReference", expected.TaxReference, actual.TaxReference)
-	equalsString(t, "avsResponseCode", expected.AVSResponseCode, actual.AVSResponseCode)
+	equalsString(t, "avsResponseCode", string(expected.AVSResponseCode), string(actual.AVSResponseCode))
 }
 
 func compareCardholderInfo(t *testing.T, expected, actual card.CardHolderInfo) {
@@ -1188,7 +1188,7 @@ func compareCardholderInfo(t *testing.T, expected, actual card.CardHolderInfo) {
 
 func compareCreditCard(t *testing.T, expected, actual card.CreditCardResponse) {
 	equalsString(t, "cardLastFourDigits", expected.CardLastFourDigits, actual.CardLastFourDigits)
-	equalsString(t, "cardType", expected.CardType, actual.CardType)
+	equalsString(t, "cardType", string(expected.CardType), string(actual.CardType))
 	equalsString(t, "cardSubType", expected.CardSubType, actual.CardSubType)
 	equalsString(t, "cardCategory", expected.CardCategory, actual.CardCategory)
 	equalsString(t, "binCategory", expected.BinCategory, actual.BinCategory)
@@ -1236,7 +1236,7 @@ func compareWalletTokenizedCard(t *testing.T, expected, actual *card.TokenizedCa
 		return
 	}
 	equalsString(t, "cardLastFourDigits", expected.CardLastFourDigits, actual.CardLastFourDigits)
-	equalsString(t, "cardType", expected.CardType, actual.CardType)
+	equalsString(t, "cardType", string(expected.CardType), string(actual.CardType))
 	equalsString(t, "cardSubType", expected.CardSubType, actual.CardSubType)
 	equalsString(t, "binCategory", expected.BinCategory, actual.BinCategory)
 	equalsString(t, "cardRegulated", expected.CardRegulated, actual.CardRegulated)
@@ -1280,7 +1280,7 @@ func compareVendorsInfo(t *testing.T, expected, actual card.VendorsInfo) {
 func compareVendorInfo(t *testing.T, expected, actual card.VendorInfo) {
 	equalsInt64(t, "vendorId", expected.VendorId, actual.VendorId)
 	equalsFloat64(t, "commissionPercent", expected.CommissionPercent, actual.CommissionPercent)
-	equalsInt64(t, "commissionAmount", expected.CommissionAmount, actual.CommissionAmount)
+	equalsString(t, "commissionAmount", string(expected.CommissionAmount), string(actual.CommissionAmount))
 }
 
 func compareFraudResultInfo(t *testing.T, expected, actual card.FraudResultInfo) {
@@ -1288,12 +1288,12 @@ func compareFraudResultInfo(t *testing.T, expected, actual card.FraudResultInfo)
 }
 
 func compareProcessingInfo(t *testing.T, expected, actual card.ProcessingInfo) {
-	equalsString(t, "processingStatus", expected.ProcessingStatus, actual.ProcessingStatus)
-	equalsString(t, "cvvResponseCode", expected.CVVResponseCode, actual.CVVResponseCode)
+	equalsString(t, "processingStatus", string(expected.ProcessingStatus), string(actual.ProcessingStatus))
+	equalsString(t, "cvvResponseCode", string(expected.CVVResponseCode), string(actual.CVVResponseCode))
 	equalsString(t, "authorizationCode", expected.AuthorizationCode, actual.AuthorizationCode)
-	equalsString(t, "avsResponseCodeZip", expected.AVSResponseCodeZip, actual.AVSResponseCodeZip)
-	equalsString(t, "avsResponseCodeAddress", expected.AVSResponseCodeAddress, actual.AVSResponseCodeAddress)
-	equalsString(t, "avsResponseCodeName", expected.AVSResponseCodeName, actual.AVSResponseCodeName)
+	equalsString(t, "avsResponseCodeZip", string(expected.AVSResponseCodeZip), string(actual.AVSResponseCodeZip))
+	equalsString(t, "avsResponseCodeAddress", string(expected.AVSResponseCodeAddress), string(actual.AVSResponseCodeAddress))
+	equalsString(t, "avsResponseCodeName", string(expected.AVSResponseCodeName), string(actual.AVSResponseCodeName))
 	equalsString(t, "networkTransactionId", expected.NetworkTransactionId, actual.NetworkTransactionId)
 }
 
@@ -0,0 +1,150 @@
+package bluesnap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownMerchant is returned by credentials providers that have no
+// credentials for the requested merchant.
+var ErrUnknownMerchant = errors.New("bluesnap: unknown merchant")
+
+// CredentialsProvider resolves the API credentials of a merchant. The empty
+// merchant key stands for the default account.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context, merchant string) (Credentials, error)
+}
+
+// CredentialsProviderFunc adapts a function to CredentialsProvider, e.g. to
+// read from a rotating secret store.
+type CredentialsProviderFunc func(ctx context.Context, merchant string) (Credentials, error)
+
+func (f CredentialsProviderFunc) Credentials(ctx context.Context, merchant string) (Credentials, error) {
+	return f(ctx, merchant)
+}
+
+// StaticCredentials routes merchant keys to fixed credentials.
+type StaticCredentials map[string]Credentials
+
+func (s StaticCredentials) Credentials(_ context.Context, merchant string) (Credentials, error) {
+	c, ok := s[merchant]
+	if !ok {
+		return Credentials{}, fmt.Errorf("%w: %q", ErrUnknownMerchant, merchant)
+	}
+	return c, nil
+}
+
+// EnvCredentials reads credentials from environment variables:
+// <Prefix>_USERNAME and <Prefix>_PASSWORD for the default account and
+// <Prefix>_<MERCHANT>_USERNAME and <Prefix>_<MERCHANT>_PASSWORD for a
+// merchant, its key upper-cased with non-alphanumeric characters replaced by
+// underscores. Prefix defaults to BLUESNAP.
+type EnvCredentials struct {
+	Prefix string
+}
+
+func (e EnvCredentials) Credentials(_ context.Context, merchant string) (Credentials, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "BLUESNAP"
+	}
+	if merchant != "" {
+		prefix += "_" + envKey(merchant)
+	}
+
+	c := Credentials{
+		Username: os.Getenv(prefix + "_USERNAME"),
+		Password: os.Getenv(prefix + "_PASSWORD"),
+	}
+	if c.IsZero() {
+		return Credentials{}, fmt.Errorf("%w: %q, %s_USERNAME is not set", ErrUnknownMerchant, merchant, prefix)
+	}
+	return c, nil
+}
+
+func envKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// FileCredentials reads credentials from a JSON file mapping merchant keys
+// to credentials, the empty key being the default account:
+//
+//	{"": {"username": "API_1", "password": "..."}, "store-eu": {...}}
+//
+// The file is read again whenever its modification time changes, so that
+// rotated secrets are picked up without a restart.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	creds   StaticCredentials
+}
+
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+func (f *FileCredentials) Credentials(ctx context.Context, merchant string) (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if f.creds == nil || !info.ModTime().Equal(f.modTime) {
+		data, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			return Credentials{}, err
+		}
+		var creds StaticCredentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return Credentials{}, fmt.Errorf("bluesnap: reading credentials from %s: %w", f.path, err)
+		}
+		f.creds = creds
+		f.modTime = info.ModTime()
+	}
+	return f.creds.Credentials(ctx, merchant)
+}
+
+// credentials resolves the credentials of a call: Opts.Credentials when set,
+// then c.CredentialsProvider for Opts.Merchant, then c.Credentials. A
+// provider without a default account (ErrUnknownMerchant for the empty
+// merchant) leaves it to c.Credentials.
+func (c Connector) credentials(ctx context.Context, opts Opts) (Credentials, error) {
+	if !opts.Credentials.IsZero() {
+		return opts.Credentials, nil
+	}
+	if c.CredentialsProvider != nil {
+		creds, err := c.CredentialsProvider.Credentials(ctx, opts.Merchant)
+		if err != nil && !(opts.Merchant == "" && errors.Is(err, ErrUnknownMerchant)) {
+			return Credentials{}, err
+		}
+		if !creds.IsZero() {
+			return creds, nil
+		}
+	} else if opts.Merchant != "" {
+		return Credentials{}, fmt.Errorf("%w: %q, no credentials provider configured", ErrUnknownMerchant, opts.Merchant)
+	}
+	return c.Credentials, nil
+}
+
+func (c Credentials) IsZero() bool {
+	return c.Username == "" && c.Password == ""
+}
@@ -0,0 +1,130 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+var errSecretStore = errors.New("secret store unavailable")
+
+func TestConnectorCredentials(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	def := Credentials{Username: "default", Password: "pw"}
+	us := Credentials{Username: "us", Password: "pw"}
+	eu := Credentials{Username: "eu", Password: "pw"}
+	override := Credentials{Username: "override", Password: "pw"}
+
+	tests := []struct {
+		name     string
+		provider CredentialsProvider
+		opts     Opts
+		want     Credentials
+		err      error
+	}{
+		{"default", nil, Opts{}, def, nil},
+		{"override", nil, Opts{Credentials: override}, override, nil},
+		{"merchant without provider", nil, Opts{Merchant: "us"}, Credentials{}, ErrUnknownMerchant},
+		{"routed", StaticCredentials{"us": us, "eu": eu}, Opts{Merchant: "eu"}, eu, nil},
+		{"routed override", StaticCredentials{"us": us}, Opts{Merchant: "us", Credentials: override}, override, nil},
+		{"unknown merchant", StaticCredentials{"us": us}, Opts{Merchant: "eu"}, Credentials{}, ErrUnknownMerchant},
+		{"static without default", StaticCredentials{"us": us}, Opts{}, def, nil},
+		{"env without default", EnvCredentials{Prefix: "BLUESNAP_TEST_UNSET"}, Opts{}, def, nil},
+		{"provider failure", CredentialsProviderFunc(func(ctx context.Context, merchant string) (Credentials, error) {
+			return Credentials{}, errSecretStore
+		}), Opts{}, Credentials{}, errSecretStore},
+		{"provider default", CredentialsProviderFunc(func(ctx context.Context, merchant string) (Credentials, error) {
+			return Credentials{}, nil
+		}), Opts{}, def, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth = ""
+			c := New(srv.Client(), srv.URL)
+			c.Credentials = def
+			c.CredentialsProvider = test.provider
+
+			err := c.Sale(card.Request{}, &card.Response{}, test.opts)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+			if test.err != nil {
+				if auth != "" {
+					t.Error("no request should be sent")
+				}
+				return
+			}
+			if auth != "Basic "+test.want.Parse() {
+				t.Errorf("expected credentials of %s", test.want.Username)
+			}
+		})
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	os.Setenv("TESTBS_USERNAME", "default")
+	os.Setenv("TESTBS_PASSWORD", "pw")
+	os.Setenv("TESTBS_STORE_EU_USERNAME", "eu")
+	os.Setenv("TESTBS_STORE_EU_PASSWORD", "pw-eu")
+	defer func() {
+		for _, k := range []string{"TESTBS_USERNAME", "TESTBS_PASSWORD", "TESTBS_STORE_EU_USERNAME", "TESTBS_STORE_EU_PASSWORD"} {
+			os.Unsetenv(k)
+		}
+	}()
+
+	p := EnvCredentials{Prefix: "TESTBS"}
+	if c, err := p.Credentials(context.Background(), ""); err != nil || c.Username != "default" {
+		t.Errorf("unexpected default credentials %v, %v", c, err)
+	}
+	if c, err := p.Credentials(context.Background(), "store-eu"); err != nil || c.Username != "eu" || c.Password != "pw-eu" {
+		t.Errorf("unexpected merchant credentials %v, %v", c, err)
+	}
+	if _, err := p.Credentials(context.Background(), "store-us"); !errors.Is(err, ErrUnknownMerchant) {
+		t.Errorf("expected ErrUnknownMerchant, got %v", err)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bluesnap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "credentials.json")
+	write := func(data string, modTime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	now := time.Now()
+	write(`{"": {"username": "default", "password": "old"}}`, now.Add(-time.Hour))
+	p := NewFileCredentials(path)
+	if c, err := p.Credentials(context.Background(), ""); err != nil || c.Password != "old" {
+		t.Errorf("unexpected credentials %v, %v", c, err)
+	}
+
+	write(`{"": {"username": "default", "password": "new"}, "eu": {"username": "eu", "password": "pw"}}`, now)
+	if c, err := p.Credentials(context.Background(), ""); err != nil || c.Password != "new" {
+		t.Errorf("rotated credentials should be picked up, got %v, %v", c, err)
+	}
+	if c, err := p.Credentials(context.Background(), "eu"); err != nil || c.Username != "eu" {
+		t.Errorf("unexpected merchant credentials %v, %v", c, err)
+	}
+}
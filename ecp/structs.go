@@ -0,0 +1,58 @@
+// Package ecp holds the ACH/ECP (electronic check) transactions of the
+// alt-transactions API. ECP payments are sales only, they cannot be
+// authorized and captured.
+package ecp
+
+import "github.com/metricsglobal/bluesnap/money"
+
+// Request charges a US bank account, given in full or, for a vaulted
+// shopper, by its public account and routing numbers.
+type Request struct {
+	Amount                money.Decimal `json:"amount"`
+	Currency              string        `json:"currency"`
+	VaultedShopperID      int64         `json:"vaultedShopperId,omitempty"`
+	MerchantTransactionID string        `json:"merchantTransactionId,omitempty"`
+	SoftDescriptor        string        `json:"softDescriptor,omitempty"`
+	PayerInfo             *PayerInfo    `json:"payerInfo,omitempty"`
+	ECPTransaction        *Transaction  `json:"ecpTransaction"`
+	// AuthorizedByShopper states that the shopper authorized the debit, as
+	// required by NACHA rules.
+	AuthorizedByShopper bool `json:"authorizedByShopper"`
+}
+
+// Transaction identifies the bank account to debit.
+type Transaction struct {
+	AccountNumber       string `json:"accountNumber,omitempty"`
+	RoutingNumber       string `json:"routingNumber,omitempty"`
+	AccountType         string `json:"accountType,omitempty"`
+	PublicAccountNumber string `json:"publicAccountNumber,omitempty"`
+	PublicRoutingNumber string `json:"publicRoutingNumber,omitempty"`
+}
+
+type PayerInfo struct {
+	FirstName   string `json:"firstName,omitempty"`
+	LastName    string `json:"lastName,omitempty"`
+	CompanyName string `json:"companyName,omitempty"`
+	Zip         string `json:"zip,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+type Response struct {
+	TransactionID         string         `json:"transactionId"`
+	VaultedShopperID      int64          `json:"vaultedShopperId"`
+	Amount                money.Decimal  `json:"amount"`
+	Currency              string         `json:"currency"`
+	MerchantTransactionID string         `json:"merchantTransactionId"`
+	SoftDescriptor        string         `json:"softDescriptor"`
+	AuthorizedByShopper   bool           `json:"authorizedByShopper"`
+	PayerInfo             PayerInfo      `json:"payerInfo"`
+	ECPTransaction        Transaction    `json:"ecpTransaction"`
+	ProcessingInfo        ProcessingInfo `json:"processingInfo"`
+}
+
+// ProcessingInfo reports the status of a debit, "PENDING" until the bank
+// settles it.
+type ProcessingInfo struct {
+	ProcessingStatus string `json:"processingStatus"`
+}
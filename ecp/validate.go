@@ -0,0 +1,115 @@
+package ecp
+
+import (
+	"strconv"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// accountTypes are the accepted values of Transaction.AccountType.
+var accountTypes = []string{"CONSUMER_CHECKING", "CONSUMER_SAVINGS", "CORPORATE_CHECKING", "CORPORATE_SAVINGS"}
+
+// Validate checks that t holds either the public account and routing numbers
+// of a stored account, or a full account: digits only account number, ABA
+// routing number and account type. It returns a *card.ValidationError.
+func (t Transaction) Validate() error {
+	e := &card.ValidationError{}
+	if t.PublicAccountNumber != "" || t.PublicRoutingNumber != "" {
+		if t.PublicAccountNumber == "" {
+			e.Add("publicAccountNumber", "is required with publicRoutingNumber")
+		}
+		if t.PublicRoutingNumber == "" {
+			e.Add("publicRoutingNumber", "is required with publicAccountNumber")
+		}
+		if t.AccountNumber != "" || t.RoutingNumber != "" {
+			e.Add("accountNumber", "cannot be combined with publicAccountNumber")
+		}
+		return e.Err()
+	}
+
+	switch {
+	case t.AccountNumber == "":
+		e.Add("accountNumber", "is required")
+	case !digits(t.AccountNumber):
+		e.Add("accountNumber", "must only contain digits")
+	}
+	switch {
+	case t.RoutingNumber == "":
+		e.Add("routingNumber", "is required")
+	case len(t.RoutingNumber) != 9 || !digits(t.RoutingNumber):
+		e.Add("routingNumber", "must be 9 digits")
+	case !abaChecksum(t.RoutingNumber):
+		e.Add("routingNumber", "fails the ABA checksum")
+	}
+	if !validAccountType(t.AccountType) {
+		e.Add("accountType", "must be one of CONSUMER_CHECKING, CONSUMER_SAVINGS, CORPORATE_CHECKING or CORPORATE_SAVINGS")
+	}
+	return e.Err()
+}
+
+// Validate checks r before it is sent: a positive amount in a known currency,
+// the bank account, the payer zip code of accounts that are not vaulted, and
+// the shopper authorization. It returns a *card.ValidationError listing every
+// invalid field.
+func (r Request) Validate() error {
+	e := &card.ValidationError{}
+	c := money.Currency(r.Currency)
+	switch {
+	case r.Currency == "":
+		e.Add("currency", "is required")
+	case !c.Valid():
+		e.Add("currency", "unknown ISO 4217 currency code "+strconv.Quote(r.Currency))
+	}
+	if r.Amount == "" {
+		e.Add("amount", "is required")
+	} else if c.Valid() {
+		if m, err := money.Parse(string(r.Amount), c); err != nil || m.Sign() <= 0 {
+			e.Add("amount", "must be a positive amount with at most "+strconv.Itoa(c.Exponent())+" decimals")
+		}
+	}
+
+	if r.ECPTransaction == nil {
+		e.Add("ecpTransaction", "is required")
+	} else {
+		e.Merge("ecpTransaction", r.ECPTransaction.Validate())
+		if r.ECPTransaction.PublicAccountNumber != "" && r.VaultedShopperID == 0 {
+			e.Add("vaultedShopperId", "is required to charge a stored account")
+		}
+	}
+	if r.VaultedShopperID == 0 && (r.PayerInfo == nil || r.PayerInfo.Zip == "") {
+		e.Add("payerInfo.zip", "is required without vaultedShopperId")
+	}
+	if !r.AuthorizedByShopper {
+		e.Add("authorizedByShopper", "must be true, NACHA rules require the shopper authorization")
+	}
+	return e.Err()
+}
+
+func validAccountType(t string) bool {
+	for _, a := range accountTypes {
+		if t == a {
+			return true
+		}
+	}
+	return false
+}
+
+// abaChecksum reports whether the 9 digit routing number n passes the ABA
+// check: 3, 7 and 1 weighted digits summing to a multiple of 10.
+func abaChecksum(n string) bool {
+	sum := 0
+	for i, weight := range []int{3, 7, 1, 3, 7, 1, 3, 7, 1} {
+		sum += int(n[i]-'0') * weight
+	}
+	return sum%10 == 0
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,61 @@
+package bluesnap
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Environment is the base URL of a BlueSnap API environment. Besides Sandbox
+// and Production any URL can be used, e.g. for a proxy or a local fake.
+type Environment string
+
+const (
+	Sandbox    Environment = "https://sandbox.bluesnap.com"
+	Production Environment = "https://ws.bluesnap.com"
+)
+
+// ParseEnvironment accepts "sandbox", "production" or an absolute URL.
+func ParseEnvironment(s string) (Environment, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "sandbox":
+		return Sandbox, nil
+	case "production":
+		return Production, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("bluesnap: invalid environment %q, expected sandbox, production or an absolute URL", s)
+	}
+	return Environment(strings.TrimRight(s, "/")), nil
+}
+
+func (e Environment) URL() string {
+	return string(e)
+}
+
+// IsProduction reports whether e points to the BlueSnap production API.
+func (e Environment) IsProduction() bool {
+	u, err := url.Parse(string(e))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "ws.bluesnap.com" || strings.HasSuffix(host, ".ws.bluesnap.com")
+}
+
+func (e *Environment) UnmarshalText(text []byte) error {
+	env, err := ParseEnvironment(string(text))
+	if err != nil {
+		return err
+	}
+	*e = env
+	return nil
+}
+
+// NewForEnvironment is New with a typed Environment.
+func NewForEnvironment(client *http.Client, env Environment) Connector {
+	return New(client, env.URL())
+}
@@ -0,0 +1,99 @@
+package bluesnap
+
+//go:generate go run ./internal/errorcodesgen -in errorcodes.csv -out errorcodes_catalog.go
+
+// ErrorCode is the numeric code of an ErrorMessage.
+type ErrorCode int64
+
+// ErrorName is the errorName of an ErrorMessage. For payment processing
+// failures (CodePaymentProcessingFailure) it carries the decline reason.
+type ErrorName string
+
+// DeclineType classifies how final a decline is.
+type DeclineType int
+
+const (
+	// NotDeclined is used for errors that are not issuer or processor declines.
+	NotDeclined DeclineType = iota
+	// SoftDecline means the same card may succeed later or after the shopper
+	// corrects their details.
+	SoftDecline
+	// HardDecline means the card must not be charged again.
+	HardDecline
+)
+
+func (d DeclineType) String() string {
+	switch d {
+	case SoftDecline:
+		return "soft"
+	case HardDecline:
+		return "hard"
+	}
+	return "none"
+}
+
+// ErrorInfo describes a known BlueSnap error.
+type ErrorInfo struct {
+	Code ErrorCode
+	Name ErrorName
+	// ShopperMessage is safe to display to the shopper.
+	ShopperMessage string
+	// Retryable reports whether resubmitting the same request right away may
+	// succeed, which is only the case for technical failures. Issuer declines
+	// are never retryable: charging the card again soon after, if at all, is
+	// a business decision that card networks police.
+	Retryable bool
+	Decline   DeclineType
+}
+
+const genericShopperMessage = "We could not process your payment. Please try again or use a different payment method."
+
+// LookupError returns the catalog entry for m. Unknown error names fall back
+// to a classification derived from the error code, in which case ok is false.
+func LookupError(m ErrorMessage) (info ErrorInfo, ok bool) {
+	if info, ok := errorCatalog[ErrorName(m.ErrorName)]; ok {
+		return info, true
+	}
+
+	info = ErrorInfo{
+		Code:           ErrorCode(m.Code),
+		Name:           ErrorName(m.ErrorName),
+		ShopperMessage: genericShopperMessage,
+	}
+	switch ErrorCode(m.Code) {
+	case CodePaymentProcessingFailure:
+		info.Decline = SoftDecline
+	case CodeFraudDetected:
+		info.Decline = HardDecline
+	}
+	return info, false
+}
+
+// Info returns the classification of m, see LookupError.
+func (m ErrorMessage) Info() ErrorInfo {
+	info, _ := LookupError(m)
+	return info
+}
+
+// Decline returns the primary decline reason of e: the first message
+// classified as a decline, preferring known error names. ok is false when no
+// message is a decline.
+func (e Errors) Decline() (info ErrorInfo, ok bool) {
+	var fallback *ErrorInfo
+	for _, m := range e.Messages {
+		info, known := LookupError(m)
+		if info.Decline == NotDeclined {
+			continue
+		}
+		if known {
+			return info, true
+		}
+		if fallback == nil {
+			fallback = &info
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return ErrorInfo{}, false
+}
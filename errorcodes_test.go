@@ -0,0 +1,58 @@
+package bluesnap
+
+import "testing"
+
+func TestLookupError(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   ErrorMessage
+		known     bool
+		decline   DeclineType
+		retryable bool
+	}{
+		{"insufficient funds", ErrorMessage{ErrorName: "INSUFFICIENT_FUNDS", Code: 14002}, true, SoftDecline, false},
+		{"do not honor", ErrorMessage{ErrorName: "DO_NOT_HONOR", Code: 14002}, true, SoftDecline, false},
+		{"timeout", ErrorMessage{ErrorName: "PROCESSING_TIMEOUT", Code: 14002}, true, SoftDecline, true},
+		{"expired card", ErrorMessage{ErrorName: "EXPIRED_CARD", Code: 14002}, true, HardDecline, false},
+		{"fraud", ErrorMessage{ErrorName: "FRAUD_DETECTED", Code: 15011}, true, HardDecline, false},
+		{"validation", ErrorMessage{ErrorName: "VALIDATION_GENERAL_FAILURE", Code: 10001}, true, NotDeclined, false},
+		{"unknown decline", ErrorMessage{ErrorName: "SOMETHING_NEW", Code: 14002}, false, SoftDecline, false},
+		{"unknown", ErrorMessage{ErrorName: "SOMETHING_ELSE", Code: 90000}, false, NotDeclined, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, ok := LookupError(test.message)
+			if ok != test.known {
+				t.Errorf("known should be %v, instead of %v", test.known, ok)
+			}
+			if info.Decline != test.decline {
+				t.Errorf("decline should be %v, instead of %v", test.decline, info.Decline)
+			}
+			if info.Retryable != test.retryable {
+				t.Errorf("retryable should be %v, instead of %v", test.retryable, info.Retryable)
+			}
+			if info.ShopperMessage == "" {
+				t.Error("shopper message shouldn't be empty")
+			}
+		})
+	}
+}
+
+func TestErrorsDecline(t *testing.T) {
+	errs := Errors{Messages: []ErrorMessage{
+		{ErrorName: "VALIDATION_GENERAL_FAILURE", Code: 10001},
+		{ErrorName: "SOMETHING_NEW", Code: 14002},
+		{ErrorName: "CVV_ERROR", Code: 14002},
+	}}
+	info, ok := errs.Decline()
+	if !ok {
+		t.Fatal("expected a decline")
+	}
+	if info.Name != ErrorCVVError {
+		t.Errorf("decline should be %s, instead of %s", ErrorCVVError, info.Name)
+	}
+
+	if _, ok := (Errors{Messages: []ErrorMessage{{Code: 10001}}}).Decline(); ok {
+		t.Error("validation errors are not declines")
+	}
+}
@@ -0,0 +1,139 @@
+package bluesnap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error categories matched by errors.Is against errors returned from
+// Connector operations.
+var (
+	ErrDeclined       = errors.New("bluesnap: payment declined")
+	ErrFraudRejected  = errors.New("bluesnap: rejected by fraud screening")
+	ErrInvalidInput   = errors.New("bluesnap: invalid input")
+	ErrNotFound       = errors.New("bluesnap: not found")
+	ErrAuthentication = errors.New("bluesnap: authentication failed")
+	ErrRateLimited    = errors.New("bluesnap: rate limited")
+	ErrServer         = errors.New("bluesnap: server error")
+)
+
+// ErrCanceled is matched by errors returned when the context passed to a
+// Connector operation is canceled or its deadline expires before the call
+// completes. The underlying context error is still available via errors.Is.
+var ErrCanceled = errors.New("bluesnap: request canceled")
+
+// APIError is returned by Connector operations when BlueSnap answers with a
+// 4xx or 5xx status. Body holds the raw response body even when it could not
+// be decoded into Messages.
+type APIError struct {
+	Errors
+	Body   []byte
+	Header http.Header
+}
+
+func newAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	e := &APIError{
+		Body:   body,
+		Header: header,
+	}
+	// Not every error response is JSON (e.g. gateway errors), the raw body is
+	// kept either way.
+	_ = json.Unmarshal(body, &e.Errors)
+	e.StatusCode = statusCode
+	return e
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "bluesnap: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	for i, m := range e.Messages {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		if m.Code != 0 {
+			fmt.Fprintf(&b, "%d ", m.Code)
+		}
+		if m.ErrorName != "" {
+			b.WriteString(m.ErrorName + " ")
+		}
+		b.WriteString(m.Description)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Is reports whether target is the category of e.
+func (e *APIError) Is(target error) bool {
+	return target != nil && target == e.Category()
+}
+
+// Category returns one of the ErrXxx sentinels describing e, or nil when the
+// error does not fall into any known category.
+func (e *APIError) Category() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthentication
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+
+	for _, m := range e.Messages {
+		if ErrorCode(m.Code) == CodeFraudDetected || ErrorName(m.ErrorName) == ErrorFraudDetected || m.FraudEvents != "" {
+			return ErrFraudRejected
+		}
+	}
+	for _, m := range e.Messages {
+		switch ErrorCode(m.Code) {
+		case CodePaymentProcessingFailure:
+			return ErrDeclined
+		case CodeValidationFailure:
+			return ErrInvalidInput
+		}
+	}
+
+	if e.StatusCode >= 400 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+type canceledError struct {
+	cause error
+}
+
+func (e canceledError) Error() string {
+	return ErrCanceled.Error() + ": " + e.cause.Error()
+}
+
+func (e canceledError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+func (e canceledError) Unwrap() error {
+	return e.cause
+}
+
+// validationError is returned when an input fails its Validate method.
+type validationError struct {
+	cause error
+}
+
+func (e validationError) Error() string {
+	return ErrInvalidInput.Error() + ": " + e.cause.Error()
+}
+
+func (e validationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+func (e validationError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,105 @@
+package bluesnap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		category error
+		messages int
+	}{
+		{
+			name:     "declined",
+			status:   http.StatusBadRequest,
+			body:     `{"message":[{"errorName":"INSUFFICIENT_FUNDS","code":14002,"description":"Insufficient funds."}]}`,
+			category: ErrDeclined,
+			messages: 1,
+		},
+		{
+			name:     "fraud",
+			status:   http.StatusBadRequest,
+			body:     `{"message":[{"errorName":"FRAUD_DETECTED","code":15011,"description":"The request cannot be fulfilled for the current shopper."}]}`,
+			category: ErrFraudRejected,
+			messages: 1,
+		},
+		{
+			name:     "validation",
+			status:   http.StatusBadRequest,
+			body:     `{"message":[{"errorName":"VALIDATION_GENERAL_FAILURE","code":10001,"description":"'amount' value is not valid.","invalidProperty":"amount"}]}`,
+			category: ErrInvalidInput,
+			messages: 1,
+		},
+		{
+			name:     "unauthorized",
+			status:   http.StatusUnauthorized,
+			body:     `<html>Unauthorized</html>`,
+			category: ErrAuthentication,
+		},
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			body:     `{"message":[{"errorName":"TRANSACTION_NOT_FOUND","code":14011,"description":"Transaction not found."}]}`,
+			category: ErrNotFound,
+			messages: 1,
+		},
+		{
+			name:     "rate limited",
+			status:   http.StatusTooManyRequests,
+			body:     ``,
+			category: ErrRateLimited,
+		},
+		{
+			name:     "server",
+			status:   http.StatusBadGateway,
+			body:     `Bad Gateway`,
+			category: ErrServer,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", test.name)
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer srv.Close()
+
+			c := New(srv.Client(), srv.URL)
+			err := c.Sale(card.Request{}, &card.Response{}, Opts{})
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *APIError, got %v", err)
+			}
+			if apiErr.StatusCode != test.status {
+				t.Errorf("status should be %d, instead of %d", test.status, apiErr.StatusCode)
+			}
+			if len(apiErr.Messages) != test.messages {
+				t.Errorf("expected %d messages, got %d", test.messages, len(apiErr.Messages))
+			}
+			if string(apiErr.Body) != test.body {
+				t.Errorf("body should be %q, instead of %q", test.body, apiErr.Body)
+			}
+			if apiErr.Header.Get("X-Test") != test.name {
+				t.Errorf("response headers are missing")
+			}
+			if !errors.Is(err, test.category) {
+				t.Errorf("expected %v, got %v", test.category, apiErr.Category())
+			}
+			for _, other := range []error{ErrDeclined, ErrFraudRejected, ErrInvalidInput, ErrNotFound, ErrAuthentication, ErrRateLimited, ErrServer} {
+				if other != test.category && errors.Is(err, other) {
+					t.Errorf("error should not match %v", other)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Command errorcodesgen generates the catalog of BlueSnap error codes from
+// errorcodes.csv, see the header of that file for its format.
+//
+//	go run ./internal/errorcodesgen -in errorcodes.csv -out errorcodes_catalog.go
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+)
+
+type code struct {
+	value, name string
+}
+
+type errorName struct {
+	value, name, code string
+	retryable         bool
+	decline           string
+	message           string
+}
+
+var declines = map[string]string{
+	"none": "NotDeclined",
+	"soft": "SoftDecline",
+	"hard": "HardDecline",
+}
+
+func main() {
+	in := flag.String("in", "errorcodes.csv", "input CSV")
+	out := flag.String("out", "errorcodes_catalog.go", "generated Go file")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	codes, names, err := parse(f)
+	if err != nil {
+		log.Fatalf("%s: %v", *in, err)
+	}
+	src, err := generate(codes, names)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parse(r io.Reader) ([]code, []errorName, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+
+	var codes []code
+	var names []errorName
+	known := map[string]string{}
+	for i := 1; ; i++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case rec[0] == "code" && len(rec) == 3:
+			if _, err := strconv.ParseInt(rec[1], 10, 64); err != nil {
+				return nil, nil, fmt.Errorf("record %d: invalid code %q", i, rec[1])
+			}
+			codes = append(codes, code{rec[1], rec[2]})
+			known[rec[1]] = "Code" + rec[2]
+		case rec[0] == "name" && len(rec) == 7:
+			c, ok := known[rec[3]]
+			if !ok {
+				return nil, nil, fmt.Errorf("record %d: undeclared code %s", i, rec[3])
+			}
+			retryable, err := strconv.ParseBool(rec[4])
+			if err != nil {
+				return nil, nil, fmt.Errorf("record %d: invalid retryable %q", i, rec[4])
+			}
+			decline, ok := declines[rec[5]]
+			if !ok {
+				return nil, nil, fmt.Errorf("record %d: invalid decline %q", i, rec[5])
+			}
+			names = append(names, errorName{rec[1], rec[2], c, retryable, decline, rec[6]})
+		default:
+			return nil, nil, fmt.Errorf("record %d: invalid record %q", i, rec)
+		}
+	}
+	return codes, names, nil
+}
+
+func generate(codes []code, names []errorName) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by errorcodesgen from errorcodes.csv; DO NOT EDIT.\n\npackage bluesnap\n\n")
+
+	b.WriteString("// Known BlueSnap payment API error codes.\nconst (\n")
+	for _, c := range codes {
+		fmt.Fprintf(&b, "Code%s ErrorCode = %s\n", c.name, c.value)
+	}
+	b.WriteString(")\n\n// Known BlueSnap error names.\nconst (\n")
+	for _, n := range names {
+		fmt.Fprintf(&b, "Error%s ErrorName = %q\n", n.name, n.value)
+	}
+	b.WriteString(")\n\nvar errorCatalog = map[ErrorName]ErrorInfo{\n")
+	for _, n := range names {
+		msg := "genericShopperMessage"
+		if n.message != "" {
+			msg = strconv.Quote(n.message)
+		}
+		fmt.Fprintf(&b, "Error%s: {%s, Error%s, %s, %t, %s},\n", n.name, n.code, n.name, msg, n.retryable, n.decline)
+	}
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCatalogUpToDate(t *testing.T) {
+	f, err := os.Open("../../errorcodes.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	codes, names, err := parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(codes, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := ioutil.ReadFile("../../errorcodes_catalog.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, committed) {
+		t.Error("errorcodes_catalog.go is out of date, run go generate")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"code,abc,Bad",
+		"name,X,X,10001,false,none,",
+		"code,10001,A\nname,X,X,10001,maybe,none,",
+		"code,10001,A\nname,X,X,10001,false,medium,",
+		"other,1",
+	}
+	for _, test := range tests {
+		if _, _, err := parse(strings.NewReader(test)); err == nil {
+			t.Errorf("expected an error parsing %q", test)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package bluesnap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Logger is the subset of *slog.Logger used by LoggingMiddleware, so that
+// either slog or any logger with the same key-value style can be plugged in.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// LoggingMiddleware logs every call with its request and response bodies
+// passed through Redact and headers through RedactHeader, so card data and
+// credentials never reach the logs.
+func LoggingMiddleware(logger Logger, policy RedactionPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			logger.Debug("bluesnap request",
+				"operation", string(req.Operation),
+				"method", req.HTTPMethod,
+				"endpoint", req.Endpoint,
+				"header", RedactHeader(req.Header),
+				"body", string(Redact(req.Body, policy)),
+			)
+
+			start := time.Now()
+			resp, err := next(ctx, req)
+			elapsed := time.Since(start)
+			if err != nil {
+				logger.Error("bluesnap request failed",
+					"operation", string(req.Operation),
+					"duration", elapsed,
+					"error", err.Error(),
+				)
+				return resp, err
+			}
+
+			logFn := logger.Info
+			if resp.StatusCode > 399 {
+				logFn = logger.Error
+			}
+			logFn("bluesnap response",
+				"operation", string(req.Operation),
+				"status", resp.StatusCode,
+				"duration", elapsed,
+				"recovered", resp.Recovered,
+				"header", RedactHeader(resp.Header),
+				"body", string(Redact(resp.Body, policy)),
+			)
+			return resp, nil
+		}
+	}
+}
+
+// LogLevel is the minimum level of messages a leveled Logger emits.
+type LogLevel string
+
+const (
+	LogDebug LogLevel = "debug"
+	LogInfo  LogLevel = "info"
+	LogError LogLevel = "error"
+	LogOff   LogLevel = "off"
+)
+
+func (l LogLevel) rank() int {
+	switch l {
+	case LogDebug:
+		return 0
+	case LogInfo:
+		return 1
+	case LogError:
+		return 2
+	}
+	return 3
+}
+
+// Valid reports whether l is one of the known levels.
+func (l LogLevel) Valid() bool {
+	switch l {
+	case LogDebug, LogInfo, LogError, LogOff:
+		return true
+	}
+	return false
+}
+
+// NewStdLogger returns a Logger writing "LEVEL msg key=value ..." lines to l,
+// dropping messages below level.
+func NewStdLogger(l *log.Logger, level LogLevel) Logger {
+	return LevelLogger(stdLogger{l}, level)
+}
+
+type stdLogger struct {
+	l *log.Logger
+}
+
+func (s stdLogger) print(level, msg string, args []interface{}) {
+	var b strings.Builder
+	b.WriteString(level + " " + msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	s.l.Print(b.String())
+}
+
+func (s stdLogger) Debug(msg string, args ...interface{}) { s.print("DEBUG", msg, args) }
+func (s stdLogger) Info(msg string, args ...interface{})  { s.print("INFO", msg, args) }
+func (s stdLogger) Error(msg string, args ...interface{}) { s.print("ERROR", msg, args) }
+
+// LevelLogger drops the messages of logger below level.
+func LevelLogger(logger Logger, level LogLevel) Logger {
+	return levelLogger{logger, level.rank()}
+}
+
+type levelLogger struct {
+	logger Logger
+	min    int
+}
+
+func (l levelLogger) Debug(msg string, args ...interface{}) {
+	if l.min <= LogDebug.rank() {
+		l.logger.Debug(msg, args...)
+	}
+}
+
+func (l levelLogger) Info(msg string, args ...interface{}) {
+	if l.min <= LogInfo.rank() {
+		l.logger.Info(msg, args...)
+	}
+}
+
+func (l levelLogger) Error(msg string, args ...interface{}) {
+	if l.min <= LogError.rank() {
+		l.logger.Error(msg, args...)
+	}
+}
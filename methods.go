@@ -1,68 +1,326 @@
 package bluesnap
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/url"
 
 	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/ecp"
+	"github.com/metricsglobal/bluesnap/money"
+	"github.com/metricsglobal/bluesnap/vaultedshopper"
 )
 
-func (c Connector) Sale(input Serializer, output Deserializer, opts Opts) (Errors, error) {
+// altTransactionsEndpoint serves the payment methods other than cards.
+const altTransactionsEndpoint = "/services/2/alt-transactions"
+
+func (c Connector) Sale(input Serializer, output Deserializer, opts Opts) error {
+	return c.SaleContext(context.Background(), input, output, opts)
+}
+
+func (c Connector) SaleContext(ctx context.Context, input Serializer, output Deserializer, opts Opts) error {
 	if input.Method() != output.Method() {
-		return emptyErrors(), errors.New("input method differs from output method")
+		return errors.New("input method differs from output method")
 	}
 
 	switch input.Method() {
 	case card.Method:
-		return c.do("POST", "/services/2/transactions", input, output, opts)
+		return c.do(ctx, OpSale, "POST", "/services/2/transactions", input, output, opts)
+	case ecp.Method:
+		return c.do(ctx, OpSale, "POST", altTransactionsEndpoint, input, output, opts)
 	}
 
-	return emptyErrors(), errors.New("invalid method passed")
+	return errors.New("invalid method passed")
+}
+
+func (c Connector) Auth(input Serializer, output Deserializer, opts Opts) error {
+	return c.AuthContext(context.Background(), input, output, opts)
 }
 
-func (c Connector) Auth(input Serializer, output Deserializer, opts Opts) (Errors, error) {
+func (c Connector) AuthContext(ctx context.Context, input Serializer, output Deserializer, opts Opts) error {
 	if input.Method() != output.Method() {
-		return emptyErrors(), errors.New("input method differs from output method")
+		return errors.New("input method differs from output method")
 	}
 
 	switch input.Method() {
 	case card.Method:
-		return c.do("POST", "/services/2/transactions", input, output, opts)
+		return c.do(ctx, OpAuth, "POST", "/services/2/transactions", input, output, opts)
 	}
 
-	return emptyErrors(), errors.New("invalid method passed")
+	return errors.New("invalid method passed")
+}
+
+func (c Connector) Capture(input Serializer, output Deserializer, opts Opts) error {
+	return c.CaptureContext(context.Background(), input, output, opts)
 }
 
-func (c Connector) Capture(input Serializer, output Deserializer, opts Opts) (Errors, error) {
+// CaptureContext captures an authorization, input must be a
+// card.CaptureRequest.
+func (c Connector) CaptureContext(ctx context.Context, input Serializer, output Deserializer, opts Opts) error {
 	if input.Method() != output.Method() {
-		return emptyErrors(), errors.New("input method differs from output method")
+		return errors.New("input method differs from output method")
+	}
+
+	switch input.(type) {
+	case card.CaptureRequest, *card.CaptureRequest:
+		return c.do(ctx, OpCapture, "PUT", "/services/2/transactions", input, output, opts)
+	}
+
+	return errors.New("invalid input passed, expected card.CaptureRequest")
+}
+
+func (c Connector) AuthReversal(input Serializer, output Deserializer, opts Opts) error {
+	return c.AuthReversalContext(context.Background(), input, output, opts)
+}
+
+// AuthReversalContext reverses an authorization, input must be a
+// card.ReversalRequest.
+func (c Connector) AuthReversalContext(ctx context.Context, input Serializer, output Deserializer, opts Opts) error {
+	if input.Method() != output.Method() {
+		return errors.New("input method differs from output method")
+	}
+
+	switch input.(type) {
+	case card.ReversalRequest, *card.ReversalRequest:
+		return c.do(ctx, OpAuthReversal, "PUT", "/services/2/transactions", input, output, opts)
+	}
+
+	return errors.New("invalid input passed, expected card.ReversalRequest")
+}
+
+func (c Connector) UpdateAuth(input Serializer, output Deserializer, opts Opts) error {
+	return c.UpdateAuthContext(context.Background(), input, output, opts)
+}
+
+// UpdateAuthContext changes the amount of an authorization, input must be a
+// card.UpdateAuthRequest.
+func (c Connector) UpdateAuthContext(ctx context.Context, input Serializer, output Deserializer, opts Opts) error {
+	if input.Method() != output.Method() {
+		return errors.New("input method differs from output method")
+	}
+
+	switch input.(type) {
+	case card.UpdateAuthRequest, *card.UpdateAuthRequest:
+		return c.do(ctx, OpUpdateAuth, "PUT", "/services/2/transactions", input, output, opts)
+	}
+
+	return errors.New("invalid input passed, expected card.UpdateAuthRequest")
+}
+
+func (c Connector) IncrementalAuth(transactionID string, increment money.Money, output *card.Response, opts Opts) error {
+	return c.IncrementalAuthContext(context.Background(), transactionID, increment, output, opts)
+}
+
+// IncrementalAuthContext raises the amount held by an authorization by
+// increment, or lowers it when increment is negative. The current amount is
+// taken from output when it already holds the authorization, e.g. after Auth
+// or an earlier IncrementalAuth, and retrieved otherwise. output receives the
+// updated authorization.
+func (c Connector) IncrementalAuthContext(ctx context.Context, transactionID string, increment money.Money, output *card.Response, opts Opts) error {
+	current := *output
+	if current.TransactionID != transactionID || current.Currency == "" {
+		current = card.Response{}
+		if err := c.RetrieveContext(ctx, transactionID, &current, opts); err != nil {
+			return err
+		}
+	}
+
+	authorized, err := current.AuthorizedMoney()
+	if current.AuthorizedAmount.IsZero() {
+		authorized, err = current.AmountMoney()
+	}
+	if err != nil {
+		return err
+	}
+	amount, err := authorized.Add(increment)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("authorized amount would drop to %s", amount)
+	}
+
+	req := card.UpdateAuthRequest{TransactionID: transactionID}
+	req.SetMoney(amount)
+	return c.UpdateAuthContext(ctx, req, output, opts)
+}
+
+func (c Connector) Retrieve(transactionID string, output Deserializer, opts Opts) error {
+	return c.RetrieveContext(context.Background(), transactionID, output, opts)
+}
+
+func (c Connector) RetrieveContext(ctx context.Context, transactionID string, output Deserializer, opts Opts) error {
+	switch output.Method() {
+	case card.Method:
+		return c.do(ctx, OpRetrieve, "GET", "/services/2/transactions/"+url.PathEscape(transactionID), nil, output, opts)
+	case ecp.Method:
+		return c.do(ctx, OpRetrieve, "GET", altTransactionsEndpoint+"/"+url.PathEscape(transactionID), nil, output, opts)
+	}
+
+	return errors.New("invalid method passed")
+}
+
+// RetrieveByMerchantTransactionID retrieves a transaction by the
+// merchantTransactionId it was created with.
+func (c Connector) RetrieveByMerchantTransactionID(merchantTransactionID string, output Deserializer, opts Opts) error {
+	return c.RetrieveByMerchantTransactionIDContext(context.Background(), merchantTransactionID, output, opts)
+}
+
+func (c Connector) RetrieveByMerchantTransactionIDContext(ctx context.Context, merchantTransactionID string, output Deserializer, opts Opts) error {
+	endpoint, ok := merchantTransactionEndpoint(output.Method(), merchantTransactionID)
+	if !ok {
+		return errors.New("invalid method passed")
+	}
+	return c.do(ctx, OpRetrieve, "GET", endpoint, nil, output, opts)
+}
+
+// merchantTransactionEndpoint returns the endpoint retrieving a transaction
+// of the given payment method by its merchantTransactionId.
+func merchantTransactionEndpoint(method, merchantTransactionID string) (string, bool) {
+	switch method {
+	case card.Method:
+		return "/services/2/transactions/merchant/" + url.PathEscape(merchantTransactionID), true
+	}
+	return "", false
+}
+
+func (c Connector) Refund(transactionID string, input Serializer, output Deserializer, opts Opts) error {
+	return c.RefundContext(context.Background(), transactionID, input, output, opts)
+}
+
+func (c Connector) RefundContext(ctx context.Context, transactionID string, input Serializer, output Deserializer, opts Opts) error {
+	if input.Method() != output.Method() {
+		return errors.New("input method differs from output method")
 	}
 
 	switch input.Method() {
 	case card.Method:
-		return c.do("POST", "/services/2/transactions", input, output, opts)
+		return c.do(ctx, OpRefund, "POST", "/services/2/transactions/refund/"+url.PathEscape(transactionID), input, output, opts)
 	}
 
-	return emptyErrors(), errors.New("invalid method passed")
+	return errors.New("invalid method passed")
+}
+
+func (c Connector) RefundByMerchantTransactionID(merchantTransactionID string, input Serializer, output Deserializer, opts Opts) error {
+	return c.RefundByMerchantTransactionIDContext(context.Background(), merchantTransactionID, input, output, opts)
 }
 
-func (c Connector) AuthReversal(input Serializer, output Deserializer, opts Opts) (Errors, error) {
+func (c Connector) RefundByMerchantTransactionIDContext(ctx context.Context, merchantTransactionID string, input Serializer, output Deserializer, opts Opts) error {
 	if input.Method() != output.Method() {
-		return emptyErrors(), errors.New("input method differs from output method")
+		return errors.New("input method differs from output method")
 	}
 
 	switch input.Method() {
 	case card.Method:
-		return c.do("POST", "/services/2/transactions", input, output, opts)
+		return c.do(ctx, OpRefund, "POST", "/services/2/transactions/refund/merchant/"+url.PathEscape(merchantTransactionID), input, output, opts)
+	}
+
+	return errors.New("invalid method passed")
+}
+
+func (c Connector) RetrieveRefund(refundTransactionID string, output Deserializer, opts Opts) error {
+	return c.RetrieveRefundContext(context.Background(), refundTransactionID, output, opts)
+}
+
+func (c Connector) RetrieveRefundContext(ctx context.Context, refundTransactionID string, output Deserializer, opts Opts) error {
+	switch output.Method() {
+	case card.Method:
+		return c.do(ctx, OpRetrieveRefund, "GET", "/services/2/transactions/refund/"+url.PathEscape(refundTransactionID), nil, output, opts)
 	}
 
-	return emptyErrors(), errors.New("invalid method passed")
+	return errors.New("invalid method passed")
+}
+
+// CancelRefund cancels a refund that has not settled yet, see
+// card.RefundStatus.Cancellable.
+func (c Connector) CancelRefund(refundTransactionID string, output Deserializer, opts Opts) error {
+	return c.CancelRefundContext(context.Background(), refundTransactionID, output, opts)
 }
 
-func (c Connector) Retrieve(transactionID string, output Deserializer, opts Opts) (Errors, error) {
+func (c Connector) CancelRefundContext(ctx context.Context, refundTransactionID string, output Deserializer, opts Opts) error {
 	switch output.Method() {
 	case card.Method:
-		return c.do("POST", "/services/2/transactions/"+transactionID, nil, output, opts)
+		return c.do(ctx, OpCancelRefund, "PUT", "/services/2/transactions/refund/"+url.PathEscape(refundTransactionID)+"/cancel", nil, output, opts)
+	}
+
+	return errors.New("invalid method passed")
+}
+
+const vaultedShoppersEndpoint = "/services/2/vaulted-shoppers"
+
+// CreateVaultedShopper stores a shopper and its payment sources, input is a
+// vaultedshopper.Request. The new shopper ID is in the output, e.g.
+// vaultedshopper.Response.VaultedShopperID.
+func (c Connector) CreateVaultedShopper(input Serializer, output Deserializer, opts Opts) error {
+	return c.CreateVaultedShopperContext(context.Background(), input, output, opts)
+}
+
+func (c Connector) CreateVaultedShopperContext(ctx context.Context, input Serializer, output Deserializer, opts Opts) error {
+	if input.Method() != output.Method() {
+		return errors.New("input method differs from output method")
+	}
+
+	switch input.Method() {
+	case vaultedshopper.Method:
+		return c.do(ctx, OpCreateVaultedShopper, "POST", vaultedShoppersEndpoint, input, output, opts)
 	}
 
-	return emptyErrors(), errors.New("invalid method passed")
+	return errors.New("invalid method passed")
+}
+
+func (c Connector) RetrieveVaultedShopper(vaultedShopperID string, output Deserializer, opts Opts) error {
+	return c.RetrieveVaultedShopperContext(context.Background(), vaultedShopperID, output, opts)
+}
+
+func (c Connector) RetrieveVaultedShopperContext(ctx context.Context, vaultedShopperID string, output Deserializer, opts Opts) error {
+	switch output.Method() {
+	case vaultedshopper.Method:
+		return c.do(ctx, OpRetrieveVaultedShopper, "GET", vaultedShoppersEndpoint+"/"+url.PathEscape(vaultedShopperID), nil, output, opts)
+	}
+
+	return errors.New("invalid method passed")
+}
+
+// RetrieveVaultedShopperByMerchantShopperID retrieves a shopper by the
+// merchantShopperId it was created with.
+func (c Connector) RetrieveVaultedShopperByMerchantShopperID(merchantShopperID string, output Deserializer, opts Opts) error {
+	return c.RetrieveVaultedShopperByMerchantShopperIDContext(context.Background(), merchantShopperID, output, opts)
+}
+
+func (c Connector) RetrieveVaultedShopperByMerchantShopperIDContext(ctx context.Context, merchantShopperID string, output Deserializer, opts Opts) error {
+	switch output.Method() {
+	case vaultedshopper.Method:
+		return c.do(ctx, OpRetrieveVaultedShopper, "GET", vaultedShoppersEndpoint+"/merchant/"+url.PathEscape(merchantShopperID), nil, output, opts)
+	}
+
+	return errors.New("invalid method passed")
+}
+
+// UpdateVaultedShopper changes the details of a shopper and adds the payment
+// sources of input to the stored ones.
+func (c Connector) UpdateVaultedShopper(vaultedShopperID string, input Serializer, output Deserializer, opts Opts) error {
+	return c.UpdateVaultedShopperContext(context.Background(), vaultedShopperID, input, output, opts)
+}
+
+func (c Connector) UpdateVaultedShopperContext(ctx context.Context, vaultedShopperID string, input Serializer, output Deserializer, opts Opts) error {
+	if input.Method() != output.Method() {
+		return errors.New("input method differs from output method")
+	}
+
+	switch input.Method() {
+	case vaultedshopper.Method:
+		return c.do(ctx, OpUpdateVaultedShopper, "PUT", vaultedShoppersEndpoint+"/"+url.PathEscape(vaultedShopperID), input, output, opts)
+	}
+
+	return errors.New("invalid method passed")
+}
+
+// DeleteVaultedShopper deletes a shopper along with its payment sources.
+func (c Connector) DeleteVaultedShopper(vaultedShopperID string, opts Opts) error {
+	return c.DeleteVaultedShopperContext(context.Background(), vaultedShopperID, opts)
+}
+
+func (c Connector) DeleteVaultedShopperContext(ctx context.Context, vaultedShopperID string, opts Opts) error {
+	return c.do(ctx, OpDeleteVaultedShopper, "DELETE", vaultedShoppersEndpoint+"/"+url.PathEscape(vaultedShopperID), nil, nil, opts)
 }
@@ -1,10 +1,18 @@
 package bluesnap
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/ecp"
+	"github.com/metricsglobal/bluesnap/money"
+	"github.com/metricsglobal/bluesnap/vaultedshopper"
 )
 
 func TestAuthOnly(t *testing.T) {
@@ -57,8 +65,8 @@ func TestAuthOnly(t *testing.T) {
 				CardTransactionType: "AUTH_ONLY",
 			},
 			output: &card.Response{
-				Amount:    11.00,
-				USDAmount: 11.00,
+				Amount:    "11",
+				USDAmount: "11",
 				ProcessingInfo: card.ProcessingInfo{
 					AVSResponseCodeAddress: "U",
 					ProcessingStatus:       "success",
@@ -140,8 +148,8 @@ func TestAuthOnly(t *testing.T) {
 				CardTransactionType: "AUTH_ONLY",
 			},
 			output: &card.Response{
-				Amount:    11.00,
-				USDAmount: 11.00,
+				Amount:    "11",
+				USDAmount: "11",
 				ProcessingInfo: card.ProcessingInfo{
 					AVSResponseCodeAddress: "U",
 					ProcessingStatus:       "success",
@@ -188,8 +196,8 @@ func TestAuthOnly(t *testing.T) {
 				CardTransactionType: "AUTH_ONLY",
 			},
 			output: &card.Response{
-				Amount:           11.00,
-				USDAmount:        11.00,
+				Amount:           "11",
+				USDAmount:        "11",
 				VaultedShopperID: 20781033,
 				ProcessingInfo: card.ProcessingInfo{
 					AVSResponseCodeAddress: "U",
@@ -265,3 +273,476 @@ func CompareResponse(exp, got card.Response) bool {
 		exp.CreditCard.ExpirationYear == got.CreditCard.ExpirationYear &&
 		exp.CardTransactionType == got.CardTransactionType
 }
+
+func TestContextCancellation(t *testing.T) {
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	calls := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"Sale", func(ctx context.Context) error {
+			return c.SaleContext(ctx, card.Request{}, &card.Response{}, Opts{})
+		}},
+		{"Auth", func(ctx context.Context) error {
+			return c.AuthContext(ctx, card.Request{}, &card.Response{}, Opts{})
+		}},
+		{"Capture", func(ctx context.Context) error {
+			return c.CaptureContext(ctx, card.CaptureRequest{}, &card.Response{}, Opts{})
+		}},
+		{"AuthReversal", func(ctx context.Context) error {
+			return c.AuthReversalContext(ctx, card.ReversalRequest{}, &card.Response{}, Opts{})
+		}},
+		{"Retrieve", func(ctx context.Context) error {
+			return c.RetrieveContext(ctx, "1", &card.Response{}, Opts{})
+		}},
+	}
+	for _, call := range calls {
+		t.Run(call.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-started
+				cancel()
+			}()
+
+			done := make(chan error, 1)
+			go func() { done <- call.call(ctx) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, ErrCanceled) {
+					t.Errorf("expected ErrCanceled, got %v", err)
+				}
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("expected context.Canceled, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("call was not aborted by context cancellation")
+			}
+		})
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.SaleContext(ctx, card.Request{}, &card.Response{}, Opts{})
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("expected ErrCanceled, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRefund(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("refund should be a POST, got %s", r.Method)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.EscapedPath() {
+		case "/services/2/transactions/refund/1035511869":
+			if string(body) != `{}` {
+				t.Errorf("full refund should have an empty body, got %s", body)
+			}
+			w.Write([]byte(`{"refundTransactionId":1035512001,"amount":11,"currency":"USD"}`))
+		case "/services/2/transactions/refund/merchant/order%2F42":
+			expected := `{"amount":5.00,"taxAmount":0.50,"reason":"damaged","cancelSubscriptions":false,"vendorsRefundInfo":{"vendorRefundInfo":[{"vendorId":10398032,"vendorAmount":1.00}]},"transactionMetaData":{"metaData":[{"metaKey":"ticket","metaValue":"T-1"}]}}`
+			if string(body) != expected {
+				t.Errorf("expected body\n%s, got\n%s", expected, body)
+			}
+			w.Write([]byte(`{"refundTransactionId":1035512002,"amount":5,"taxAmount":0.5,"currency":"USD","reason":"damaged","vendorsRefundInfo":{"vendorRefundInfo":[{"vendorId":10398032,"vendorAmount":1.00}]}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+
+	full := card.RefundResponse{}
+	if err := c.Refund("1035511869", card.RefundRequest{}, &full, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if full.RefundTransactionId != 1035512001 || full.Amount != "11" {
+		t.Errorf("unexpected refund %+v", full)
+	}
+
+	cancel := false
+	partial := card.RefundResponse{}
+	err := c.RefundByMerchantTransactionID("order/42", card.RefundRequest{
+		Amount:              "5.00",
+		TaxAmount:           "0.50",
+		Reason:              "damaged",
+		CancelSubscriptions: &cancel,
+		VendorsRefundInfo: &card.VendorsRefundInfo{
+			VendorRefundInfo: []card.VendorRefundInfo{{VendorID: 10398032, VendorAmount: "1.00"}},
+		},
+		TransactionMetaData: &card.TransactionMetadata{
+			Metadata: []card.Metadata{{MetaKey: "ticket", MetaValue: "T-1"}},
+		},
+	}, &partial, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partial.RefundTransactionId != 1035512002 || partial.TaxAmount != "0.5" || len(partial.VendorsRefundInfo.VendorRefundInfo) != 1 {
+		t.Errorf("unexpected refund %+v", partial)
+	}
+}
+
+func TestRefundManagement(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /services/2/transactions/refund/1035512001":
+			w.Write([]byte(`{"refundTransactionId":1035512001,"amount":5,"currency":"USD","refundStatus":"PENDING"}`))
+		case "PUT /services/2/transactions/refund/1035512001/cancel":
+			w.Write([]byte(`{"refundTransactionId":1035512001,"amount":5,"currency":"USD","refundStatus":"CANCELLED"}`))
+		case "PUT /services/2/transactions/refund/1035512002/cancel":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":[{"errorName":"REFUND_ALREADY_SETTLED","code":20037,"description":"Refund cannot be cancelled."}]}`))
+		case "GET /services/2/transactions/1035511869":
+			w.Write([]byte(`{"transactionId":"1035511869","refunds":{"refund":[{"refundTransactionId":1035512001,"amount":5,"refundStatus":"PENDING"},{"refundTransactionId":1035512002,"amount":6,"refundStatus":"SUCCESS"}],"balanceAmount":0}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+
+	refund := card.RefundResponse{}
+	if err := c.RetrieveRefund("1035512001", &refund, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if refund.RefundStatus != card.RefundPending || !refund.RefundStatus.Cancellable() {
+		t.Errorf("refund should be pending, got %s", refund.RefundStatus)
+	}
+
+	transaction := card.Response{}
+	if err := c.Retrieve("1035511869", &transaction, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(transaction.Refunds.Refund) != 2 {
+		t.Errorf("unexpected refunds %+v", transaction.Refunds)
+	}
+	if settled, ok := transaction.Refund(1035512002); !ok || settled.RefundStatus != card.RefundSuccess {
+		t.Errorf("unexpected refund %+v", settled)
+	}
+	if _, ok := transaction.Refund(1); ok {
+		t.Error("unexpected refund 1")
+	}
+
+	cancelled := card.RefundResponse{}
+	if err := c.CancelRefund("1035512001", &cancelled, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.RefundStatus != card.RefundCancelled {
+		t.Errorf("refund should be cancelled, got %s", cancelled.RefundStatus)
+	}
+
+	var apiErr *APIError
+	if err := c.CancelRefund("1035512002", &card.RefundResponse{}, Opts{}); !errors.As(err, &apiErr) || !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected an invalid input API error, got %v", err)
+	}
+}
+
+func TestCaptureAndReversal(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/services/2/transactions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+
+	resp := card.Response{}
+	err := c.Capture(card.CaptureRequest{
+		TransactionID: "1035511869",
+		Amount:        "5.00",
+		VendorsInfo:   &card.VendorsInfo{VendorInfo: []card.VendorInfo{{VendorId: 10398032, CommissionPercent: 10}}},
+	}, &resp, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"cardTransactionType":"CAPTURE","transactionId":"1035511869","amount":5.00,"vendorsInfo":{"vendorInfo":[{"vendorId":10398032,"commissionPercent":10}]}}`
+	if body != expected {
+		t.Errorf("expected capture payload\n%s, got\n%s", expected, body)
+	}
+
+	if err := c.AuthReversal(&card.ReversalRequest{TransactionID: "1035511869"}, &resp, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"cardTransactionType":"AUTH_REVERSAL","transactionId":"1035511869"}`
+	if body != expected {
+		t.Errorf("expected reversal payload\n%s, got\n%s", expected, body)
+	}
+
+	if err := c.Capture(card.Request{CardTransactionType: "AUTH_CAPTURE", TransactionID: "1"}, &card.Response{}, Opts{}); err == nil {
+		t.Error("Capture should refuse a card.Request")
+	}
+	if err := c.AuthReversal(card.CaptureRequest{TransactionID: "1"}, &card.Response{}, Opts{}); err == nil {
+		t.Error("AuthReversal should refuse a card.CaptureRequest")
+	}
+}
+
+func TestRetrieve(t *testing.T) {
+	recorded, err := ioutil.ReadFile("testdata/retrieve_card_transaction.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("retrieve should be a GET, got %s", r.Method)
+		}
+		switch r.URL.EscapedPath() {
+		case "/services/2/transactions/1035511869", "/services/2/transactions/merchant/order%2F42":
+			w.Write(recorded)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":[{"errorName":"TRANSACTION_NOT_FOUND","code":14011,"description":"Transaction not found."}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	expected := card.Response{
+		CardTransactionType: "AUTH_CAPTURE",
+		Amount:              "11",
+		SoftDescriptor:      "BLS*DescTest",
+		Currency:            "USD",
+		ProcessingInfo: card.ProcessingInfo{
+			ProcessingStatus:       "success",
+			CVVResponseCode:        "MA",
+			AuthorizationCode:      "123456",
+			AVSResponseCodeZip:     "U",
+			AVSResponseCodeAddress: "U",
+			AVSResponseCodeName:    "U",
+		},
+		CreditCard: card.CreditCardResponse{
+			CardLastFourDigits: "9299",
+			CardType:           "VISA",
+			CardSubType:        "CREDIT",
+			CardCategory:       "PLATINUM",
+			BinCategory:        "CONSUMER",
+			BinNumber:          "426398",
+			CardRegulated:      "N",
+			IssuingBank:        "ALLIED IRISH BANKS PLC",
+			IssuingCountryCode: "ie",
+			ExpirationMonth:    "02",
+			ExpirationYear:     "2023",
+		},
+	}
+
+	byID := card.Response{}
+	if err := c.Retrieve("1035511869", &byID, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if !CompareResponse(expected, byID) || len(byID.Refunds.Refund) != 1 {
+		t.Errorf("Expected output: \n%#v, got: \n%#v", expected, byID)
+	}
+
+	byMerchantID := card.Response{}
+	if err := c.RetrieveByMerchantTransactionID("order/42", &byMerchantID, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if !CompareResponse(expected, byMerchantID) || byMerchantID.MerchantTransactionId != "order/42" {
+		t.Errorf("Expected output: \n%#v, got: \n%#v", expected, byMerchantID)
+	}
+
+	if err := c.Retrieve("404", &card.Response{}, Opts{}); !errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected a not found API error, got %v", err)
+	}
+	if err := c.RetrieveByMerchantTransactionID("order/43", &card.Response{}, Opts{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found API error, got %v", err)
+	}
+}
+
+func TestIncrementalAuth(t *testing.T) {
+	var bodies []string
+	retrievals := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/services/2/transactions/1035511869":
+			retrievals++
+			w.Write([]byte(`{"cardTransactionType":"AUTH_ONLY","transactionId":"1035511869","amount":10.1,"openToCapture":10.1,"currency":"USD"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/services/2/transactions":
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			amount := []string{"12.30", "12.00"}[len(bodies)-1]
+			w.Write([]byte(`{"cardTransactionType":"UPDATE_AUTH","transactionId":"1035511869","amount":` + amount + `,"openToCapture":` + amount + `,"currency":"USD"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+
+	resp := card.Response{}
+	if err := c.IncrementalAuth("1035511869", usd("2.20"), &resp, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if resp.OpenToCapture != "12.3" || resp.AuthorizedAmount != "12.3" {
+		t.Errorf("expected 12.3 open to capture and authorized, got %v and %v", resp.OpenToCapture, resp.AuthorizedAmount)
+	}
+	// The updated authorization in resp is used instead of retrieving it.
+	if err := c.IncrementalAuth("1035511869", usd("-0.30"), &resp, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`{"cardTransactionType":"UPDATE_AUTH","transactionId":"1035511869","amount":12.30}`,
+		`{"cardTransactionType":"UPDATE_AUTH","transactionId":"1035511869","amount":12.00}`,
+	}
+	for i := range expected {
+		if i >= len(bodies) || bodies[i] != expected[i] {
+			t.Errorf("expected update payloads\n%s, got\n%s", expected, bodies)
+			break
+		}
+	}
+	if retrievals != 1 {
+		t.Errorf("expected 1 retrieval, got %d", retrievals)
+	}
+
+	if err := c.IncrementalAuth("1035511869", usd("-10.10"), &card.Response{}, Opts{}); err == nil {
+		t.Error("IncrementalAuth should refuse to drop the authorized amount to zero")
+	}
+	if err := c.IncrementalAuth("1035511869", money.New(220, "EUR"), &card.Response{}, Opts{}); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if err := c.IncrementalAuth("404", usd("2.20"), &card.Response{}, Opts{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the retrieval error, got %v", err)
+	}
+	if len(bodies) != len(expected) {
+		t.Errorf("failed increments should not update the authorization, got %v", bodies[len(expected):])
+	}
+	if err := c.UpdateAuth(card.CaptureRequest{TransactionID: "1"}, &card.Response{}, Opts{}); err == nil {
+		t.Error("UpdateAuth should refuse a card.CaptureRequest")
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	invalid := card.Request{CardTransactionType: card.TransactionAuthCapture, Amount: "-1"}
+
+	if err := c.Sale(invalid, &card.Response{}, Opts{}); err != nil {
+		t.Fatalf("requests should not be validated by default, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+
+	c.ValidateRequests = true
+	err := c.Sale(invalid, &card.Response{}, Opts{})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	var verr *card.ValidationError
+	if !errors.As(err, &verr) || !verr.Has("amount") || !verr.Has("currency") || !verr.Has("creditCard") {
+		t.Errorf("expected a *card.ValidationError on amount, currency and creditCard, got %v", err)
+	}
+	if err := c.AuthReversal(card.ReversalRequest{}, &card.Response{}, Opts{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("invalid requests should not be sent, got %d calls", calls)
+	}
+
+	ecpRequest := ecp.Request{
+		Amount:              "25.00",
+		Currency:            "USD",
+		PayerInfo:           &ecp.PayerInfo{FirstName: "John", LastName: "Doe", Zip: "02453"},
+		ECPTransaction:      &ecp.Transaction{AccountNumber: "4099999992", RoutingNumber: "021000021", AccountType: "CONSUMER_CHECKING"},
+		AuthorizedByShopper: true,
+	}
+	if err := c.Sale(ecpRequest, &ecp.Response{}, Opts{}); err != nil {
+		t.Errorf("unexpected error on a valid ECP request: %v", err)
+	}
+	ecpRequest.Amount = "25.001"
+	ecpRequest.PayerInfo = nil
+	ecpRequest.ECPTransaction = &ecp.Transaction{AccountNumber: "4099999992", RoutingNumber: "021000022", AccountType: "CHECKING"}
+	ecpRequest.AuthorizedByShopper = false
+	err = c.Sale(ecpRequest, &ecp.Response{}, Opts{})
+	if !errors.As(err, &verr) || len(verr.Fields) != 5 || !verr.Has("amount") || !verr.Has("payerInfo.zip") ||
+		!verr.Has("ecpTransaction.routingNumber") || !verr.Has("ecpTransaction.accountType") || !verr.Has("authorizedByShopper") {
+		t.Errorf("expected a *card.ValidationError on amount, payerInfo, ecpTransaction and authorizedByShopper, got %v", err)
+	}
+
+	shopper := vaultedshopper.Request{
+		FirstName:       "John",
+		Country:         "us",
+		State:           "MA",
+		ShopperCurrency: "USD",
+		PaymentSources: &vaultedshopper.PaymentSources{
+			CreditCardInfo: []vaultedshopper.CreditCardInfo{
+				{CreditCard: &card.CreditCardRequest{CardNumber: "4263982640269299", ExpirationMonth: "03", ExpirationYear: "2099"}},
+				{CreditCard: &card.CreditCardRequest{CardLastFourDigits: "1111", CardType: card.CardVisa}, Status: "D"},
+			},
+			ECPDetails:          []vaultedshopper.ECPDetails{{ECP: vaultedshopper.ECP{PublicAccountNumber: "5678", PublicRoutingNumber: "0345"}, Status: "D"}},
+			SEPADirectDebitInfo: []vaultedshopper.SEPADirectDebitInfo{{SEPADirectDebit: vaultedshopper.SEPADirectDebit{IBAN: "DE89 3704 0044 0532 0130 00"}}},
+		},
+	}
+	if err := c.CreateVaultedShopper(shopper, &vaultedshopper.Response{}, Opts{}); err != nil {
+		t.Errorf("unexpected error on a valid vaulted shopper: %v", err)
+	}
+	shopper.ShopperCurrency = "ABC"
+	shopper.State = ""
+	shopper.PaymentSources.CreditCardInfo[0].CreditCard.CardNumber = "4263982640269298"
+	shopper.PaymentSources.CreditCardInfo[1].CreditCard.CardType = ""
+	shopper.PaymentSources.ECPDetails[0].ECP.PublicRoutingNumber = ""
+	shopper.PaymentSources.SEPADirectDebitInfo[0].SEPADirectDebit.IBAN = "DE89370400440532013001"
+	shopper.LastPaymentInfo = &vaultedshopper.LastPaymentInfo{PaymentMethod: vaultedshopper.SourceECP}
+	err = c.UpdateVaultedShopper("19549018", shopper, &vaultedshopper.Response{}, Opts{})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	fields := []string{
+		"shopperCurrency",
+		"state",
+		"paymentSources.creditCardInfo[0].creditCard.cardNumber",
+		"paymentSources.creditCardInfo[1].creditCard",
+		"paymentSources.ecpDetails[0].ecp.publicRoutingNumber",
+		"paymentSources.sepaDirectDebitInfo[0].sepaDirectDebit.iban",
+		"lastPaymentInfo.ecp",
+	}
+	if !errors.As(err, &verr) || len(verr.Fields) != len(fields) {
+		t.Fatalf("expected a *card.ValidationError on %d fields, got %v", len(fields), err)
+	}
+	for _, f := range fields {
+		if !verr.Has(f) {
+			t.Errorf("expected %s to be invalid, got %v", f, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("invalid requests should not be sent, got %d calls", calls)
+	}
+}
@@ -0,0 +1,243 @@
+package bluesnap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallMetrics describes a finished call.
+type CallMetrics struct {
+	Operation Operation
+	// Method is the payment method, see Serializer.Method.
+	Method string
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	// ProcessingStatus is the processingInfo.processingStatus of the response,
+	// if any.
+	ProcessingStatus string
+	// ErrorCode classifies failures: the BlueSnap error name (or code) of API
+	// errors, "canceled" or "network" otherwise. Empty on success.
+	ErrorCode string
+	Duration  time.Duration
+}
+
+// MetricsCollector receives the metrics of every call made through
+// MetricsMiddleware.
+type MetricsCollector interface {
+	ObserveCall(m CallMetrics)
+}
+
+// MetricsMiddleware reports every call to collector.
+func MetricsMiddleware(collector MetricsCollector) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+
+			m := CallMetrics{
+				Operation: req.Operation,
+				Method:    paymentMethod(req),
+				Duration:  time.Since(start),
+			}
+			switch {
+			case errors.Is(err, ErrCanceled):
+				m.ErrorCode = "canceled"
+			case err != nil:
+				m.ErrorCode = "network"
+			default:
+				m.StatusCode = resp.StatusCode
+				m.ProcessingStatus = processingStatus(resp.Body)
+				if resp.StatusCode > 399 {
+					m.ErrorCode = errorCode(newAPIError(resp.StatusCode, resp.Header, resp.Body))
+				}
+			}
+			collector.ObserveCall(m)
+
+			return resp, err
+		}
+	}
+}
+
+func paymentMethod(req *APIRequest) string {
+	if req.Input != nil {
+		return req.Input.Method()
+	}
+	if req.Output != nil {
+		return req.Output.Method()
+	}
+	return ""
+}
+
+func processingStatus(body []byte) string {
+	var r struct {
+		ProcessingInfo struct {
+			ProcessingStatus string `json:"processingStatus"`
+		} `json:"processingInfo"`
+	}
+	if json.Unmarshal(body, &r) != nil {
+		return ""
+	}
+	return r.ProcessingInfo.ProcessingStatus
+}
+
+// errorCode returns the most relevant error name of e.
+func errorCode(e *APIError) string {
+	if info, ok := e.Decline(); ok && info.Name != "" {
+		return string(info.Name)
+	}
+	for _, m := range e.Messages {
+		if m.ErrorName != "" {
+			return m.ErrorName
+		}
+		if m.Code != 0 {
+			return strconv.FormatInt(m.Code, 10)
+		}
+	}
+	return "http_" + strconv.Itoa(e.StatusCode)
+}
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the call
+// duration histogram.
+var DefaultDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// MetricsRegistry is an in-memory MetricsCollector. It counts calls in
+// bluesnap_requests_total and records durations in the
+// bluesnap_request_duration_seconds histogram, and serves both in the
+// Prometheus text format.
+type MetricsRegistry struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[requestLabels]uint64
+	durations map[durationLabels]*histogram
+}
+
+type requestLabels struct {
+	Operation        Operation
+	Method           string
+	StatusCode       int
+	ProcessingStatus string
+	ErrorCode        string
+}
+
+type durationLabels struct {
+	Operation  Operation
+	Method     string
+	StatusCode int
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMetricsRegistry creates a MetricsRegistry using the given histogram
+// buckets, DefaultDurationBuckets when none are given.
+func NewMetricsRegistry(buckets ...float64) *MetricsRegistry {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &MetricsRegistry{
+		buckets:   buckets,
+		requests:  map[requestLabels]uint64{},
+		durations: map[durationLabels]*histogram{},
+	}
+}
+
+func (r *MetricsRegistry) ObserveCall(m CallMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests[requestLabels{m.Operation, m.Method, m.StatusCode, m.ProcessingStatus, m.ErrorCode}]++
+
+	key := durationLabels{m.Operation, m.Method, m.StatusCode}
+	h, ok := r.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(r.buckets))}
+		r.durations[key] = h
+	}
+	secs := m.Duration.Seconds()
+	for i, upper := range r.buckets {
+		if secs <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += secs
+}
+
+// Requests returns the number of observed calls matching filter. Zero fields
+// of filter match any value, use StatusCode -1 to match calls without a
+// response.
+func (r *MetricsRegistry) Requests(filter CallMetrics) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n uint64
+	for l, count := range r.requests {
+		if (filter.Operation == "" || filter.Operation == l.Operation) &&
+			(filter.Method == "" || filter.Method == l.Method) &&
+			(filter.StatusCode == 0 || filter.StatusCode == l.StatusCode || filter.StatusCode == -1 && l.StatusCode == 0) &&
+			(filter.ProcessingStatus == "" || filter.ProcessingStatus == l.ProcessingStatus) &&
+			(filter.ErrorCode == "" || filter.ErrorCode == l.ErrorCode) {
+			n += count
+		}
+	}
+	return n
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (r *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP bluesnap_requests_total BlueSnap API calls.\n")
+	b.WriteString("# TYPE bluesnap_requests_total counter\n")
+	var lines []string
+	for l, count := range r.requests {
+		lines = append(lines, fmt.Sprintf("bluesnap_requests_total{operation=%q,method=%q,status=%q,processing_status=%q,error_code=%q} %d\n",
+			l.Operation, l.Method, strconv.Itoa(l.StatusCode), l.ProcessingStatus, l.ErrorCode, count))
+	}
+	sort.Strings(lines)
+	b.WriteString(strings.Join(lines, ""))
+
+	b.WriteString("# HELP bluesnap_request_duration_seconds BlueSnap API call duration.\n")
+	b.WriteString("# TYPE bluesnap_request_duration_seconds histogram\n")
+	lines = nil
+	for l, h := range r.durations {
+		labels := fmt.Sprintf("operation=%q,method=%q,status=%q", l.Operation, l.Method, strconv.Itoa(l.StatusCode))
+		var s strings.Builder
+		for i, upper := range r.buckets {
+			fmt.Fprintf(&s, "bluesnap_request_duration_seconds_bucket{%s,le=%q} %d\n", labels, strconv.FormatFloat(upper, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&s, "bluesnap_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&s, "bluesnap_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&s, "bluesnap_request_duration_seconds_count{%s} %d\n", labels, h.count)
+		lines = append(lines, s.String())
+	}
+	sort.Strings(lines)
+	b.WriteString(strings.Join(lines, ""))
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP exposes the metrics to a Prometheus scraper.
+func (r *MetricsRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteTo(w)
+}
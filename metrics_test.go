@@ -0,0 +1,88 @@
+package bluesnap
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	fake := func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			switch req.Operation {
+			case OpSale:
+				return &APIResponse{StatusCode: http.StatusOK, Body: []byte(`{"processingInfo":{"processingStatus":"success"}}`)}, nil
+			case OpAuth:
+				return &APIResponse{StatusCode: http.StatusBadRequest, Body: []byte(`{"message":[{"code":14002,"errorName":"INSUFFICIENT_FUNDS"}]}`)}, nil
+			case OpRetrieve:
+				return nil, canceledError{cause: context.Canceled}
+			}
+			return &APIResponse{StatusCode: http.StatusBadGateway, Body: []byte(`Bad Gateway`)}, nil
+		}
+	}
+
+	registry := NewMetricsRegistry(0.5, 1)
+	c := New(nil, testURL)
+	c.Middleware = []Middleware{MetricsMiddleware(registry), fake}
+
+	c.Sale(card.Request{}, &card.Response{}, Opts{})
+	c.Sale(card.Request{}, &card.Response{}, Opts{})
+	c.Auth(card.Request{}, &card.Response{}, Opts{})
+	c.Capture(card.CaptureRequest{}, &card.Response{}, Opts{})
+	c.Retrieve("1", &card.Response{}, Opts{})
+
+	tests := []struct {
+		name   string
+		filter CallMetrics
+		want   uint64
+	}{
+		{"all", CallMetrics{}, 5},
+		{"card", CallMetrics{Method: card.Method}, 5},
+		{"sales", CallMetrics{Operation: OpSale, StatusCode: 200, ProcessingStatus: "success"}, 2},
+		{"declines", CallMetrics{Operation: OpAuth, StatusCode: 400, ErrorCode: "INSUFFICIENT_FUNDS"}, 1},
+		{"server errors", CallMetrics{Operation: OpCapture, StatusCode: 502, ErrorCode: "http_502"}, 1},
+		{"canceled", CallMetrics{Operation: OpRetrieve, StatusCode: -1, ErrorCode: "canceled"}, 1},
+	}
+	for _, test := range tests {
+		if got := registry.Requests(test.filter); got != test.want {
+			t.Errorf("%s: expected %d, got %d", test.name, test.want, got)
+		}
+	}
+
+	var b strings.Builder
+	registry.WriteTo(&b)
+	out := b.String()
+	for _, line := range []string{
+		`bluesnap_requests_total{operation="Sale",method="card",status="200",processing_status="success",error_code=""} 2`,
+		`bluesnap_request_duration_seconds_bucket{operation="Sale",method="card",status="200",le="0.5"} 2`,
+		`bluesnap_request_duration_seconds_count{operation="Sale",method="card",status="200"} 2`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected %s in\n%s", line, out)
+		}
+	}
+}
+
+func TestMetricsRegistryHistogram(t *testing.T) {
+	registry := NewMetricsRegistry(1, 0.1)
+	for _, d := range []time.Duration{50 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		registry.ObserveCall(CallMetrics{Operation: OpSale, Method: card.Method, StatusCode: 200, Duration: d})
+	}
+
+	var b strings.Builder
+	registry.WriteTo(&b)
+	for _, line := range []string{
+		`le="0.1"} 1`,
+		`le="1"} 2`,
+		`le="+Inf"} 3`,
+		`bluesnap_request_duration_seconds_sum{operation="Sale",method="card",status="200"} 2.55`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("expected %s in\n%s", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package bluesnap
+
+import (
+	"context"
+	"net/http"
+)
+
+// Operation names the Connector operation a call is made for.
+type Operation string
+
+const (
+	OpSale           Operation = "Sale"
+	OpAuth           Operation = "Auth"
+	OpCapture        Operation = "Capture"
+	OpAuthReversal   Operation = "AuthReversal"
+	OpUpdateAuth     Operation = "UpdateAuth"
+	OpRetrieve       Operation = "Retrieve"
+	OpRefund         Operation = "Refund"
+	OpRetrieveRefund Operation = "RetrieveRefund"
+	OpCancelRefund   Operation = "CancelRefund"
+
+	OpCreateVaultedShopper   Operation = "CreateVaultedShopper"
+	OpRetrieveVaultedShopper Operation = "RetrieveVaultedShopper"
+	OpUpdateVaultedShopper   Operation = "UpdateVaultedShopper"
+	OpDeleteVaultedShopper   Operation = "DeleteVaultedShopper"
+)
+
+// APIRequest is a call to the BlueSnap API as seen by Middleware.
+type APIRequest struct {
+	Operation  Operation
+	HTTPMethod string
+	Endpoint   string
+	// Input is nil for calls without a body, such as retrievals and the
+	// lookups made by retries.
+	Input  Serializer
+	Output Deserializer
+	Opts   Opts
+	// Header is sent on top of the authorization and content type headers.
+	Header http.Header
+	Body   []byte
+}
+
+// APIResponse is the raw answer to an APIRequest. Status codes above 399 are
+// turned into an *APIError and successful bodies are decoded into
+// APIRequest.Output once the whole chain returns.
+type APIResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Recovered is set when the response is an existing transaction found
+	// after an ambiguous failure.
+	Recovered bool
+}
+
+// Handler performs an APIRequest.
+type Handler func(ctx context.Context, req *APIRequest) (*APIResponse, error)
+
+// Middleware wraps a Handler, e.g. to log, measure or fake calls. A
+// Middleware that does not call next must return a response on its own.
+type Middleware func(next Handler) Handler
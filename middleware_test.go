@@ -0,0 +1,78 @@
+package bluesnap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+func TestMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "signed" {
+			t.Error("header added by middleware was not sent")
+		}
+		w.Write([]byte(`{"transactionId":"1"}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+				calls = append(calls, name+" "+string(req.Operation))
+				if req.Input == nil || req.Input.Method() != card.Method || req.Output == nil {
+					t.Error("middleware should see the input and output")
+				}
+				return next(ctx, req)
+			}
+		}
+	}
+	sign := func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			req.Header.Set("X-Signature", "signed")
+			return next(ctx, req)
+		}
+	}
+
+	c := New(srv.Client(), srv.URL)
+	c.Middleware = []Middleware{record("outer"), record("inner"), sign}
+
+	resp := card.Response{}
+	if err := c.Auth(card.Request{}, &resp, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TransactionID != "1" {
+		t.Errorf("transactionId should be 1, instead of %s", resp.TransactionID)
+	}
+	if len(calls) != 2 || calls[0] != "outer Auth" || calls[1] != "inner Auth" {
+		t.Errorf("unexpected middleware calls %v", calls)
+	}
+}
+
+func TestMiddlewareFake(t *testing.T) {
+	fake := func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			if req.Operation == OpCapture {
+				return &APIResponse{StatusCode: http.StatusBadRequest, Body: []byte(`{"message":[{"code":14002,"errorName":"EXPIRED_CARD"}]}`)}, nil
+			}
+			return &APIResponse{StatusCode: http.StatusOK, Body: []byte(`{"transactionId":"fake"}`)}, nil
+		}
+	}
+
+	c := New(nil, "http://unreachable.invalid")
+	c.Middleware = []Middleware{fake}
+
+	resp := card.Response{}
+	if err := c.Sale(card.Request{}, &resp, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TransactionID != "fake" {
+		t.Errorf("transactionId should be fake, instead of %s", resp.TransactionID)
+	}
+	if err := c.Capture(card.CaptureRequest{}, &card.Response{}, Opts{}); err == nil {
+		t.Error("expected the faked API error")
+	}
+}
@@ -0,0 +1,9 @@
+package bluesnap
+
+import "github.com/metricsglobal/bluesnap/money"
+
+// Money is an exact amount in minor units of a currency, see package money.
+type Money = money.Money
+
+// Currency is an ISO 4217 currency code.
+type Currency = money.Currency
@@ -0,0 +1,158 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number without a currency, the form of the
+// amounts of BlueSnap documents whose currency is given by another field. It
+// is encoded as a JSON number and decoded from a JSON number or string
+// without going through float64, in its shortest form, e.g. "12.5" for 12.50.
+// Decode amounts into a Decimal and convert them with Money once their
+// currency is known.
+// The empty Decimal is zero and is left out by omitempty.
+type Decimal string
+
+// ParseDecimal reads a decimal number such as "12.50", "-3" or "1.5e2".
+func ParseDecimal(s string) (Decimal, error) {
+	d, ok := normalize(strings.TrimSpace(s))
+	if !ok {
+		return "", fmt.Errorf("money: invalid decimal %q", s)
+	}
+	return d, nil
+}
+
+// normalize returns the shortest form of the decimal number s.
+func normalize(s string) (Decimal, bool) {
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > 100 || e < -100 {
+			return "", false
+		}
+		exp, s = e, s[:i]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || !digits(whole) || !digits(frac) {
+		return "", false
+	}
+
+	// Move the decimal point by exp, padding with zeros.
+	all := whole + frac
+	point := len(whole) + exp
+	if point < 0 {
+		all = strings.Repeat("0", -point) + all
+		point = 0
+	}
+	if point > len(all) {
+		all += strings.Repeat("0", point-len(all))
+	}
+	whole = strings.TrimLeft(all[:point], "0")
+	frac = strings.TrimRight(all[point:], "0")
+	if whole == "" {
+		whole = "0"
+	}
+
+	d := whole
+	if frac != "" {
+		d += "." + frac
+	}
+	if neg && d != "0" {
+		d = "-" + d
+	}
+	return Decimal(d), true
+}
+
+// Decimal returns the amount of m as a Decimal.
+func (m Money) Decimal() Decimal {
+	d, _ := normalize(m.Amount())
+	return d
+}
+
+// Money returns d in currency c, rounded half away from zero to the currency
+// exponent as BlueSnap may report converted amounts with more decimals.
+func (d Decimal) Money(c Currency) (Money, error) {
+	return parse(d.String(), c, true)
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	n, ok := normalize(string(d))
+	switch {
+	case !ok || n == "0":
+		return 0
+	case n[0] == '-':
+		return -1
+	}
+	return 1
+}
+
+// Float64 returns d as a float64, for APIs that require one.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) String() string {
+	if d == "" {
+		return "0"
+	}
+	return string(d)
+}
+
+// MarshalJSON encodes d as written when it is a plain JSON number, e.g.
+// 25.00 as set by SetMoney methods, and in its shortest form otherwise.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if jsonNumber(d.String()) {
+		return []byte(d.String()), nil
+	}
+	n, ok := normalize(d.String())
+	if !ok {
+		return nil, fmt.Errorf("money: invalid decimal %q", string(d))
+	}
+	return []byte(n), nil
+}
+
+// jsonNumber reports whether s is a JSON number without exponent.
+func jsonNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+		if frac == "" {
+			return false
+		}
+	}
+	return whole != "" && digits(whole) && digits(frac) && (whole == "0" || whole[0] != '0')
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	n, ok := normalize(s)
+	if !ok {
+		return fmt.Errorf("money: invalid amount %s", data)
+	}
+	*d = n
+	return nil
+}
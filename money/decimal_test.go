@@ -0,0 +1,94 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s   string
+		d   Decimal
+		err bool
+	}{
+		{"12.50", "12.5", false},
+		{"012.500", "12.5", false},
+		{"-0.10", "-0.1", false},
+		{"-0", "0", false},
+		{".5", "0.5", false},
+		{"11.0", "11", false},
+		{"1.5e2", "150", false},
+		{"125E-3", "0.125", false},
+		{"1e-2", "0.01", false},
+		{"0.1234567890123456789", "0.1234567890123456789", false},
+		{"abc", "", true},
+		{"", "", true},
+		{"1.2.3", "", true},
+		{"1e1000", "", true},
+	}
+	for _, test := range tests {
+		d, err := ParseDecimal(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseDecimal(%q) should fail, got %s", test.s, d)
+			}
+			continue
+		}
+		if err != nil || d != test.d {
+			t.Errorf("ParseDecimal(%q): expected %s, got %s (%v)", test.s, test.d, d, err)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+		C Decimal `json:"c,omitempty"`
+		D Decimal `json:"d"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":12.50,"b":"0.125","d":0.1}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != "12.5" || v.B != "0.125" || v.C != "" || v.D != "0.1" {
+		t.Errorf("unexpected decimals %+v", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":12.5,"b":0.125,"d":0.1}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a":"12,5"}`), &v); err == nil {
+		t.Error("expected an error decoding 12,5")
+	}
+	for d, expected := range map[Decimal]string{"25.00": "25.00", "-0.50": "-0.50", "1.5e2": "150", "007": "7", ".5": "0.5"} {
+		if data, err := json.Marshal(d); err != nil || string(data) != expected {
+			t.Errorf("encoding %q: expected %s, got %s (%v)", string(d), expected, data, err)
+		}
+	}
+	if _, err := json.Marshal(Decimal("twelve")); err == nil {
+		t.Error("expected an error encoding an invalid decimal")
+	}
+}
+
+func TestDecimalMoney(t *testing.T) {
+	m, err := Decimal("12.5").Money("USD")
+	if err != nil || m != New(1250, "USD") {
+		t.Errorf("expected 12.50 USD, got %s (%v)", m, err)
+	}
+	if m, _ := Decimal("1.0055").Money("KWD"); m != New(1006, "KWD") {
+		t.Errorf("expected 1.006 KWD, got %s", m)
+	}
+	if m, _ := Decimal("").Money("JPY"); !m.IsZero() || m.Currency() != "JPY" {
+		t.Errorf("expected 0 JPY, got %s", m)
+	}
+	if d := New(1230, "USD").Decimal(); d != "12.3" {
+		t.Errorf("expected 12.3, got %s", d)
+	}
+	if Decimal("-0.01").Sign() != -1 || !Decimal("0.00").IsZero() || Decimal("").Sign() != 0 {
+		t.Error("unexpected sign")
+	}
+}
@@ -0,0 +1,219 @@
+// Package money represents amounts exactly, as an integer number of minor
+// units of an ISO 4217 currency, instead of the strings and float64 used on
+// the BlueSnap wire.
+package money
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned by operations combining amounts of
+// different currencies.
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// Money is an amount in minor units of a currency, e.g. 1230 USD for 12.30
+// USD or 1230 JPY for ¥1230. The zero value is zero in no currency.
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// New returns minor units of currency c.
+func New(minor int64, c Currency) Money {
+	return Money{minor, c}
+}
+
+// Parse reads a decimal amount such as "12.30" or "-5" in currency c. More
+// fraction digits than the currency exponent are refused unless they are
+// zeros.
+func Parse(amount string, c Currency) (Money, error) {
+	return parse(amount, c, false)
+}
+
+// FromFloat converts a float64 amount as found in BlueSnap responses, using
+// its shortest decimal representation so that e.g. 10.1 is 1010 cents, and
+// rounds half away from zero to the currency exponent.
+func FromFloat(f float64, c Currency) (Money, error) {
+	return parse(strconv.FormatFloat(f, 'f', -1, 64), c, true)
+}
+
+func parse(amount string, c Currency, round bool) (Money, error) {
+	s := strings.TrimSpace(amount)
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || !digits(whole) || !digits(frac) {
+		return Money{}, fmt.Errorf("money: invalid amount %q", amount)
+	}
+
+	exp := c.Exponent()
+	roundUp := false
+	if len(frac) > exp {
+		extra := frac[exp:]
+		frac = frac[:exp]
+		if strings.Trim(extra, "0") != "" {
+			if !round {
+				return Money{}, fmt.Errorf("money: %q has more than %d decimals for %s", amount, exp, c)
+			}
+			roundUp = extra[0] >= '5'
+		}
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	n := strings.TrimLeft(whole+frac, "0")
+	if n == "" {
+		n = "0"
+	}
+	minor, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("money: amount %q out of range", amount)
+	}
+	if roundUp {
+		minor++
+	}
+	if neg {
+		minor = -minor
+	}
+	return Money{minor, c}, nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of m.
+func (m Money) Sign() int {
+	switch {
+	case m.minor < 0:
+		return -1
+	case m.minor > 0:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) Neg() Money {
+	return Money{-m.minor, m.currency}
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return Money{m.minor + o.minor, m.currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Neg())
+}
+
+// Mul multiplies m by n, e.g. a unit price by a quantity.
+func (m Money) Mul(n int64) Money {
+	return Money{m.minor * n, m.currency}
+}
+
+// Cmp returns -1, 0 or 1 when m is lower than, equal to or greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	d, err := m.Sub(o)
+	if err != nil {
+		return 0, err
+	}
+	return d.Sign(), nil
+}
+
+// Amount formats m as a decimal with the currency exponent, e.g. "12.30",
+// as BlueSnap expects in requests.
+func (m Money) Amount() string {
+	exp := m.currency.Exponent()
+	n := m.minor
+	sign := ""
+	if n < 0 {
+		sign = "-"
+	}
+	s := strconv.FormatUint(abs(n), 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+func abs(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+// Float64 returns m as a float64, for APIs that require one.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.Amount(), 64)
+	return f
+}
+
+// String formats m with its currency, e.g. "12.30 USD".
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.Amount()
+	}
+	return m.Amount() + " " + string(m.currency)
+}
+
+// MarshalJSON encodes m as a JSON number, the format of BlueSnap amount
+// fields.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Amount()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string in the currency m already
+// has, BlueSnap sending the currency in a separate field. Fraction digits
+// beyond the currency exponent are rounded. Decoding into a Money without
+// currency fails, as its exponent is unknown: decode into a Decimal instead.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if m.currency == "" {
+		return fmt.Errorf("money: cannot decode %s without a currency, decode it into a Decimal", data)
+	}
+	var d Decimal
+	if err := d.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	v, err := d.Money(m.currency)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
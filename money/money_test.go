@@ -0,0 +1,151 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount    string
+		currency  Currency
+		minor     int64
+		formatted string
+		err       bool
+	}{
+		{"12.30", "USD", 1230, "12.30", false},
+		{"12.3", "USD", 1230, "12.30", false},
+		{"-5", "EUR", -500, "-5.00", false},
+		{"0.01", "USD", 1, "0.01", false},
+		{".5", "USD", 50, "0.50", false},
+		{"1230", "JPY", 1230, "1230", false},
+		{"1230.00", "JPY", 1230, "1230", false},
+		{"1230.5", "JPY", 0, "", true},
+		{"1.234", "KWD", 1234, "1.234", false},
+		{"0.005", "KWD", 5, "0.005", false},
+		{"1.001", "USD", 0, "", true},
+		{"abc", "USD", 0, "", true},
+		{"", "USD", 0, "", true},
+		{"1e3", "USD", 0, "", true},
+		{"99999999999999999999", "USD", 0, "", true},
+	}
+	for _, test := range tests {
+		m, err := Parse(test.amount, test.currency)
+		if test.err {
+			if err == nil {
+				t.Errorf("Parse(%q, %s) should fail, got %v", test.amount, test.currency, m)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q, %s): %v", test.amount, test.currency, err)
+			continue
+		}
+		if m.Minor() != test.minor || m.Amount() != test.formatted {
+			t.Errorf("Parse(%q, %s): expected %d (%s), got %d (%s)", test.amount, test.currency, test.minor, test.formatted, m.Minor(), m.Amount())
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		f        float64
+		currency Currency
+		minor    int64
+	}{
+		{10.1, "USD", 1010},
+		{0.29, "USD", 29},
+		{19.99, "EUR", 1999},
+		{1.005, "USD", 101},
+		{-1.005, "USD", -101},
+		{1500, "JPY", 1500},
+		{12.3456, "KWD", 12346},
+	}
+	for _, test := range tests {
+		m, err := FromFloat(test.f, test.currency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Minor() != test.minor {
+			t.Errorf("FromFloat(%v, %s): expected %d, got %d", test.f, test.currency, test.minor, m.Minor())
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := New(1010, "USD"), New(20, "USD")
+
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "10.30 USD" {
+		t.Errorf("expected 10.30 USD, got %s (%v)", sum, err)
+	}
+	diff, _ := b.Sub(a)
+	if diff.String() != "-9.90 USD" || diff.Sign() != -1 {
+		t.Errorf("expected -9.90 USD, got %s", diff)
+	}
+	if m := b.Mul(3); m.Minor() != 60 {
+		t.Errorf("expected 60, got %d", m.Minor())
+	}
+	if c, _ := a.Cmp(b); c != 1 {
+		t.Errorf("expected %s > %s", a, b)
+	}
+	if c, _ := a.Cmp(a); c != 0 {
+		t.Errorf("expected %s == %s", a, a)
+	}
+	if _, err := a.Add(New(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if s := New(-5, "KWD").Amount(); s != "-0.005" {
+		t.Errorf("expected -0.005, got %s", s)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{New(1230, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":12.30}` {
+		t.Errorf("unexpected encoding %s", data)
+	}
+
+	for _, in := range []string{`12.3`, `"12.30"`, `1.23e1`} {
+		m := New(0, "USD")
+		if err := json.Unmarshal([]byte(in), &m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Minor() != 1230 || m.Currency() != "USD" {
+			t.Errorf("decoding %s: expected 12.30 USD, got %s", in, m)
+		}
+	}
+
+	m := New(0, "JPY")
+	if err := json.Unmarshal([]byte(`1500`), &m); err != nil || m.Minor() != 1500 {
+		t.Errorf("expected 1500 JPY, got %s (%v)", m, err)
+	}
+	if err := json.Unmarshal([]byte(`"abc"`), &m); err == nil {
+		t.Error("decoding an invalid amount should fail")
+	}
+	m = New(0, "KWD")
+	if err := json.Unmarshal([]byte(`1.005`), &m); err != nil || m.Minor() != 1005 {
+		t.Errorf("expected 1.005 KWD, got %s (%v)", m, err)
+	}
+	m = Money{}
+	if err := json.Unmarshal([]byte(`1500`), &m); err == nil {
+		t.Errorf("decoding without currency should fail, got %s", m)
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	for c, exp := range map[Currency]int{"USD": 2, "JPY": 0, "KWD": 3, "CLF": 4, "UYI": 0, "XXX": 2} {
+		if got := c.Exponent(); got != exp {
+			t.Errorf("%s: expected exponent %d, got %d", c, exp, got)
+		}
+	}
+	if !Currency("EUR").Valid() || Currency("usd").Valid() || Currency("XXX").Valid() {
+		t.Error("unexpected currency validity")
+	}
+}
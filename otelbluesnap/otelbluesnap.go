@@ -0,0 +1,81 @@
+// Package otelbluesnap adapts an OpenTelemetry tracer to bluesnap.Tracer, so
+// that the spans of bluesnap.TracingMiddleware are exported with the rest of
+// an application's traces. It is a separate module to keep the bluesnap
+// module free of dependencies.
+//
+//	c.Middleware = append(c.Middleware, bluesnap.TracingMiddleware(
+//		otelbluesnap.NewTracer(otel.GetTracerProvider()),
+//	))
+package otelbluesnap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/metricsglobal/bluesnap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer obtained by NewTracer.
+const InstrumentationName = "github.com/metricsglobal/bluesnap"
+
+// NewTracer returns a bluesnap.Tracer starting spans with the tracer of tp
+// named InstrumentationName.
+func NewTracer(tp trace.TracerProvider) bluesnap.Tracer {
+	return Tracer(tp.Tracer(InstrumentationName))
+}
+
+// Tracer returns a bluesnap.Tracer starting client spans with t.
+func Tracer(t trace.Tracer) bluesnap.Tracer {
+	return tracer{t}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, bluesnap.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, span{s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttributes(attrs ...bluesnap.Attribute) {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		kvs[i] = keyValue(a)
+	}
+	s.s.SetAttributes(kvs...)
+}
+
+// RecordError records err as an exception event and marks the span as
+// failed.
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.s.End()
+}
+
+func keyValue(a bluesnap.Attribute) attribute.KeyValue {
+	switch v := a.Value.(type) {
+	case string:
+		return attribute.String(a.Key, v)
+	case bool:
+		return attribute.Bool(a.Key, v)
+	case int:
+		return attribute.Int(a.Key, v)
+	case int64:
+		return attribute.Int64(a.Key, v)
+	case float64:
+		return attribute.Float64(a.Key, v)
+	}
+	return attribute.String(a.Key, fmt.Sprint(a.Value))
+}
@@ -0,0 +1,78 @@
+package otelbluesnap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap"
+	"github.com/metricsglobal/bluesnap/card"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":[{"code":14002,"errorName":"EXPIRED_CARD"}]}`))
+			return
+		}
+		w.Write([]byte(`{"transactionId":"1035511869","amount":11.5,"currency":"USD"}`))
+	}))
+	defer srv.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	c := bluesnap.New(srv.Client(), srv.URL)
+	c.Middleware = []bluesnap.Middleware{bluesnap.TracingMiddleware(NewTracer(tp))}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "checkout")
+	if err := c.SaleContext(ctx, card.Request{Amount: "11.50", Currency: "USD"}, &card.Response{}, bluesnap.Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RetrieveContext(ctx, "1035511870", &card.Response{}, bluesnap.Opts{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	sale, retrieve := spans[0], spans[1]
+	if sale.Name() != "bluesnap.Sale" || sale.SpanKind() != trace.SpanKindClient {
+		t.Errorf("unexpected span %s of kind %s", sale.Name(), sale.SpanKind())
+	}
+	if sale.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("span should be a child of the caller's span")
+	}
+	attrs := attribute.NewSet(sale.Attributes()...)
+	for k, v := range map[string]attribute.Value{
+		bluesnap.AttrOperation:     attribute.StringValue("Sale"),
+		bluesnap.AttrAmount:        attribute.StringValue("11.50"),
+		bluesnap.AttrStatusCode:    attribute.IntValue(200),
+		bluesnap.AttrTransactionID: attribute.StringValue("1035511869"),
+	} {
+		if got, _ := attrs.Value(attribute.Key(k)); got != v {
+			t.Errorf("%s should be %v, instead of %v", k, v.Emit(), got.Emit())
+		}
+	}
+
+	if retrieve.Status().Code != codes.Error {
+		t.Errorf("failed call should have an error status, got %v", retrieve.Status())
+	}
+	attrs = attribute.NewSet(retrieve.Attributes()...)
+	if v, _ := attrs.Value(bluesnap.AttrTransactionID); v.AsString() != "1035511870" {
+		t.Errorf("unexpected transaction ID %q", v.AsString())
+	}
+	if v, _ := attrs.Value(bluesnap.AttrErrorCode); v.AsString() != "EXPIRED_CARD" {
+		t.Errorf("unexpected error code %q", v.AsString())
+	}
+}
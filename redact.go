@@ -0,0 +1,172 @@
+package bluesnap
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/metricsglobal/bluesnap/card/bin"
+)
+
+const redacted = "[REDACTED]"
+
+// RedactionPolicy controls what Redact hides besides card numbers, security
+// codes and secrets, which are always hidden.
+type RedactionPolicy struct {
+	// MaskPII masks the values of PIIFields.
+	MaskPII bool
+	// PIIFields lists the JSON keys holding personal data, DefaultPIIFields
+	// when nil. Keys are matched case-insensitively at any depth.
+	PIIFields []string
+}
+
+var DefaultPIIFields = []string{
+	"firstName", "lastName", "email", "phone", "address", "address1", "address2", "address_2",
+	"city", "zip", "personalIdentificationNumber", "companyName", "shopperIpAddress",
+}
+
+// Keys whose values are always replaced.
+var secretFields = map[string]bool{
+	"securitycode":          true,
+	"encryptedsecuritycode": true,
+	"encryptedcardnumber":   true,
+	"cvv":                   true,
+	"password":              true,
+	"pin":                   true,
+	"encodedpaymenttoken":   true,
+}
+
+// Keys holding card numbers, truncated even when they fail the Luhn check.
+var panFields = map[string]bool{
+	"cardnumber": true,
+}
+
+// Keys holding account numbers, of which only the last four digits are kept.
+var accountFields = map[string]bool{
+	"accountnumber": true,
+	"iban":          true,
+}
+
+var panPattern = regexp.MustCompile(`\d{13,19}`)
+
+// Redact returns a copy of the JSON document body safe to log: card numbers,
+// wherever they appear, are truncated to BIN and last four digits, security
+// codes, passwords and tokens are replaced, account numbers are masked and,
+// depending on policy, personal data is masked. Bodies that are not JSON only
+// have card numbers truncated.
+func Redact(body []byte, policy RedactionPolicy) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return []byte(truncatePANs(string(body)))
+	}
+
+	pii := map[string]bool{}
+	if policy.MaskPII {
+		fields := policy.PIIFields
+		if fields == nil {
+			fields = DefaultPIIFields
+		}
+		for _, f := range fields {
+			pii[strings.ToLower(f)] = true
+		}
+	}
+
+	out, err := json.Marshal(redactValue("", doc, pii))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return out
+}
+
+func redactValue(key string, v interface{}, pii map[string]bool) interface{} {
+	k := strings.ToLower(key)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for field, value := range v {
+			v[field] = redactValue(field, value, pii)
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			// Array elements are redacted as if they were the array itself.
+			v[i] = redactValue(key, value, pii)
+		}
+		return v
+	}
+
+	switch {
+	case secretFields[k]:
+		return redacted
+	case panFields[k]:
+		return truncatePAN(toString(v))
+	case accountFields[k]:
+		return maskLast4(toString(v))
+	case pii[k]:
+		return "***"
+	}
+
+	switch s := v.(type) {
+	case string:
+		return truncatePANs(s)
+	case json.Number:
+		if t := truncatePANs(s.String()); t != s.String() {
+			return t
+		}
+	}
+	return v
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+func maskLast4(s string) string {
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+}
+
+// truncatePANs replaces every digit run in s that looks like a card number
+// with its BIN and last four digits.
+func truncatePANs(s string) string {
+	return panPattern.ReplaceAllStringFunc(s, func(pan string) string {
+		if !bin.Luhn(pan) {
+			return pan
+		}
+		return truncatePAN(pan)
+	})
+}
+
+// truncatePAN keeps the BIN and last four digits of pan.
+func truncatePAN(pan string) string {
+	if len(pan) < 13 {
+		return maskLast4(pan)
+	}
+	return bin.Mask(pan)
+}
+
+// RedactHeader returns a copy of h without credentials.
+func RedactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"} {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
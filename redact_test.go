@@ -0,0 +1,151 @@
+package bluesnap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		policy RedactionPolicy
+		want   string
+	}{
+		{
+			name: "card request",
+			body: `{"amount":"11","creditCard":{"cardNumber":"4263982640269299","securityCode":"837","expirationMonth":"02"}}`,
+			want: `{"amount":"11","creditCard":{"cardNumber":"426398******9299","expirationMonth":"02","securityCode":"[REDACTED]"}}`,
+		},
+		{
+			name: "card number failing luhn",
+			body: `{"cardNumber":"4111111111111112"}`,
+			want: `{"cardNumber":"411111******1112"}`,
+		},
+		{
+			name: "pan in unknown field and array",
+			body: `{"newMethod":{"pans":["5555555555554444", "not a pan"],"note":"card 4012000033330026 used"}}`,
+			want: `{"newMethod":{"note":"card 401200******0026 used","pans":["555555******4444","not a pan"]}}`,
+		},
+		{
+			name: "numeric pan",
+			body: `{"x":4263982640269299,"transactionId":1035511869}`,
+			want: `{"transactionId":1035511869,"x":"426398******9299"}`,
+		},
+		{
+			name: "secrets",
+			body: `{"encryptedSecurityCode":"abc","encryptedCardNumber":"def","password":"pw","wallet":{"encodedPaymentToken":"tok"}}`,
+			want: `{"encryptedCardNumber":"[REDACTED]","encryptedSecurityCode":"[REDACTED]","password":"[REDACTED]","wallet":{"encodedPaymentToken":"[REDACTED]"}}`,
+		},
+		{
+			name: "account numbers",
+			body: `{"ecp":{"accountNumber":"4099999992","routingNumber":"011075150"},"sepa":{"iban":"DE09100100101234567893"}}`,
+			want: `{"ecp":{"accountNumber":"******9992","routingNumber":"011075150"},"sepa":{"iban":"******************7893"}}`,
+		},
+		{
+			name: "pii kept by default",
+			body: `{"cardHolderInfo":{"firstName":"John","email":"john@example.com"}}`,
+			want: `{"cardHolderInfo":{"email":"john@example.com","firstName":"John"}}`,
+		},
+		{
+			name:   "pii masked",
+			body:   `{"cardHolderInfo":{"firstName":"John","email":"john@example.com","country":"US"},"billingContactInfo":{"address1":"1 Main St","zip":"02453"}}`,
+			policy: RedactionPolicy{MaskPII: true},
+			want:   `{"billingContactInfo":{"address1":"***","zip":"***"},"cardHolderInfo":{"country":"US","email":"***","firstName":"***"}}`,
+		},
+		{
+			name:   "custom pii fields",
+			body:   `{"cardHolderInfo":{"firstName":"John","email":"john@example.com"}}`,
+			policy: RedactionPolicy{MaskPII: true, PIIFields: []string{"EMAIL"}},
+			want:   `{"cardHolderInfo":{"email":"***","firstName":"John"}}`,
+		},
+		{
+			name: "not json",
+			body: `<error>card 4263982640269299 declined</error>`,
+			want: `<error>card 426398******9299 declined</error>`,
+		},
+		{
+			name: "empty",
+			body: ``,
+			want: ``,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := string(Redact([]byte(test.body), test.policy)); got != test.want {
+				t.Errorf("expected\n%s, got\n%s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Basic "+testCredentials)
+	h.Set("Accept", "application/json")
+
+	got := RedactHeader(h)
+	if got.Get("Authorization") != redacted {
+		t.Errorf("authorization header should be redacted, got %s", got.Get("Authorization"))
+	}
+	if got.Get("Accept") != "application/json" {
+		t.Error("other headers should be kept")
+	}
+	if h.Get("Authorization") == redacted {
+		t.Error("original header should not be modified")
+	}
+}
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) log(level, msg string, args ...interface{}) {
+	l.lines = append(l.lines, level+" "+msg+" "+fmt.Sprint(args...))
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args...) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args...) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args...) }
+
+func TestLoggingMiddleware(t *testing.T) {
+	logger := &testLogger{}
+	fake := func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			body, _ := json.Marshal(card.Response{CreditCard: card.CreditCardResponse{CardLastFourDigits: "9299"}})
+			return &APIResponse{StatusCode: http.StatusOK, Body: body}, nil
+		}
+	}
+
+	c := New(nil, testURL)
+	c.Middleware = []Middleware{LoggingMiddleware(logger, RedactionPolicy{MaskPII: true}), fake}
+	input := card.Request{
+		CardHolderInfo: &card.CardHolderInfo{Email: "john@example.com"},
+		CreditCard: &card.CreditCardRequest{
+			CardNumber:   "4263982640269299",
+			SecurityCode: "837",
+		},
+	}
+	if err := c.Sale(input, &card.Response{}, Opts{Credentials: Credentials{Username: testUsername, Password: testPassword}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logger.lines) != 2 {
+		t.Fatalf("expected request and response to be logged, got %v", logger.lines)
+	}
+	out := strings.Join(logger.lines, "\n")
+	for _, secret := range []string{"4263982640269299", "837", "john@example.com", testPassword, testCredentials} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "426398******9299") {
+		t.Errorf("log should contain the truncated card number: %s", out)
+	}
+}
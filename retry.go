@@ -0,0 +1,256 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures retries of failed calls with exponential backoff.
+//
+// Connection errors, 5xx and 429 responses are retried. Requests with a body
+// are only retried when they implement Idempotent with a non-empty key: before
+// resubmitting after an ambiguous failure the transaction is looked up by
+// that key, and returned instead when it already exists, so a retry never
+// creates a duplicate charge.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, values below 2 disable
+	// retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, 100ms when zero.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, no cap when zero.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each attempt, 2 when below 1.
+	Multiplier float64
+	// Jitter randomizes each wait by up to the given fraction, e.g. 0.2 for
+	// +/-20%.
+	Jitter float64
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// Idempotent is implemented by requests that carry a key BlueSnap can look
+// them up by, e.g. card.Request and its merchantTransactionId.
+type Idempotent interface {
+	IdempotencyKey() string
+}
+
+// next reports whether the outcome of the given attempt should be retried and
+// how long to wait before doing so.
+func (p RetryPolicy) next(attempt int, resp *APIResponse, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrCanceled) {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return 0, false
+	}
+
+	wait := p.backoff(attempt)
+	if after, ok := retryAfter(resp.Header); ok && after > wait {
+		wait = after
+	}
+	return wait, true
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// ambiguous reports whether a failed attempt may have been processed by
+// BlueSnap anyway.
+func ambiguous(resp *APIResponse, err error) bool {
+	return err != nil || resp.StatusCode >= 500
+}
+
+// retry is the built-in Middleware applying c.Retry and c.RecoverDuplicates.
+func (c Connector) retry(next Handler) Handler {
+	return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+		for attempt := 1; ; attempt++ {
+			resp, err := next(ctx, req)
+			wait, retry := c.Retry.next(attempt, resp, err)
+			if !retry || !retrySafe(req) {
+				if c.RecoverDuplicates && ambiguous(resp, err) && !errors.Is(err, context.Canceled) {
+					if found, lerr := recoverLookup(ctx, next, req); lerr == nil && found != nil {
+						return found, nil
+					}
+				}
+				return resp, err
+			}
+
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+
+			// The failed attempt may still have been processed, look it up
+			// before submitting it again.
+			if req.Input != nil && ambiguous(resp, err) {
+				found, lerr := lookup(ctx, next, req)
+				if lerr != nil {
+					return resp, err
+				}
+				if found != nil {
+					return found, nil
+				}
+			}
+		}
+	}
+}
+
+func retrySafe(req *APIRequest) bool {
+	if req.HTTPMethod == http.MethodGet {
+		return true
+	}
+	if req.Input == nil {
+		return false
+	}
+	_, ok := lookupEndpoint(req.Input)
+	return ok
+}
+
+// lookupEndpoint returns the endpoint retrieving the transaction created by
+// input, if input can be looked up.
+func lookupEndpoint(input Serializer) (string, bool) {
+	i, ok := input.(Idempotent)
+	if !ok || i.IdempotencyKey() == "" {
+		return "", false
+	}
+	return merchantTransactionEndpoint(input.Method(), i.IdempotencyKey())
+}
+
+// Recoverable is implemented by outputs that can be flagged as recovered
+// from an earlier attempt rather than created by the call that returned them.
+type Recoverable interface {
+	MarkRecovered()
+}
+
+// recoveryTimeout bounds the lookup made by RecoverDuplicates once the
+// deadline of the call has expired.
+const recoveryTimeout = 5 * time.Second
+
+// recoverLookup is lookup for RecoverDuplicates. The call may have failed
+// because its deadline expired while BlueSnap committed the transaction, so
+// the lookup keeps the values of ctx but gets a deadline of its own.
+func recoverLookup(ctx context.Context, next Handler, req *APIRequest) (*APIResponse, error) {
+	ctx, cancel := context.WithTimeout(detachedContext{ctx}, recoveryTimeout)
+	defer cancel()
+	return lookup(ctx, next, req)
+}
+
+// detachedContext carries the values of a context without its deadline and
+// cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// lookup retrieves the transaction previously submitted with req. It returns
+// a nil response when BlueSnap does not know the transaction.
+func lookup(ctx context.Context, next Handler, req *APIRequest) (*APIResponse, error) {
+	if req.Input == nil {
+		return nil, errors.New("input cannot be looked up")
+	}
+	endpoint, ok := lookupEndpoint(req.Input)
+	if !ok {
+		return nil, errors.New("input cannot be looked up")
+	}
+
+	resp, err := next(ctx, &APIRequest{
+		Operation:  req.Operation,
+		HTTPMethod: http.MethodGet,
+		Endpoint:   endpoint,
+		Output:     req.Output,
+		Opts:       req.Opts,
+		Header:     http.Header{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode > 399 {
+		apiErr := newAPIError(resp.StatusCode, resp.Header, resp.Body)
+		if errors.Is(apiErr, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, apiErr
+	}
+
+	resp.Recovered = true
+	return resp, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return canceledError{cause: ctx.Err()}
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,286 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	Multiplier:     2,
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    card.Request
+		statuses []int
+		lookup   int
+		posts    int32
+		lookups  int32
+		wantErr  bool
+	}{
+		{
+			name:     "5xx then success",
+			input:    card.Request{MerchantTransactionID: "order-1"},
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			lookup:   http.StatusNotFound,
+			posts:    2,
+			lookups:  1,
+		},
+		{
+			name:     "5xx but already processed",
+			input:    card.Request{MerchantTransactionID: "order-2"},
+			statuses: []int{http.StatusInternalServerError},
+			lookup:   http.StatusOK,
+			posts:    1,
+			lookups:  1,
+		},
+		{
+			name:     "429 is not looked up",
+			input:    card.Request{MerchantTransactionID: "order-3"},
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			posts:    2,
+		},
+		{
+			name:     "no merchantTransactionId",
+			input:    card.Request{},
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			posts:    1,
+			wantErr:  true,
+		},
+		{
+			name:     "lookup failure stops retries",
+			input:    card.Request{MerchantTransactionID: "order-4"},
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			lookup:   http.StatusUnauthorized,
+			posts:    1,
+			lookups:  1,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid lookup is not a missing transaction",
+			input:    card.Request{MerchantTransactionID: "order-4"},
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			lookup:   http.StatusBadRequest,
+			posts:    1,
+			lookups:  1,
+			wantErr:  true,
+		},
+		{
+			name:     "4xx is not retried",
+			input:    card.Request{MerchantTransactionID: "order-5"},
+			statuses: []int{http.StatusBadRequest, http.StatusOK},
+			posts:    1,
+			wantErr:  true,
+		},
+		{
+			name:     "max attempts",
+			input:    card.Request{MerchantTransactionID: "order-6"},
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			lookup:   http.StatusNotFound,
+			posts:    3,
+			lookups:  2,
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var posts, lookups int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					atomic.AddInt32(&lookups, 1)
+					if r.URL.Path != "/services/2/transactions/merchant/"+test.input.MerchantTransactionID {
+						t.Errorf("unexpected lookup path %s", r.URL.Path)
+					}
+					w.WriteHeader(test.lookup)
+					w.Write([]byte(`{"transactionId":"existing"}`))
+					return
+				}
+				n := atomic.AddInt32(&posts, 1)
+				w.WriteHeader(test.statuses[n-1])
+				w.Write([]byte(`{"transactionId":"new"}`))
+			}))
+			defer srv.Close()
+
+			c := New(srv.Client(), srv.URL)
+			c.Retry = testRetryPolicy
+			resp := card.Response{}
+			err := c.Sale(test.input, &resp, Opts{})
+			if (err != nil) != test.wantErr {
+				t.Errorf("unexpected error %v", err)
+			}
+			if posts != test.posts {
+				t.Errorf("expected %d submissions, got %d", test.posts, posts)
+			}
+			if lookups != test.lookups {
+				t.Errorf("expected %d lookups, got %d", test.lookups, lookups)
+			}
+			if test.lookup == http.StatusOK && resp.TransactionID != "existing" {
+				t.Errorf("expected the existing transaction, got %s", resp.TransactionID)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var posts int32
+	var first time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&posts, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if time.Since(first) < time.Second {
+			t.Error("Retry-After was not honored")
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	c.Retry = testRetryPolicy
+	if err := c.Sale(card.Request{MerchantTransactionID: "order"}, &card.Response{}, Opts{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, e := range expected {
+		if d := p.backoff(i + 1); d != e {
+			t.Errorf("backoff for attempt %d should be %v, instead of %v", i+1, e, d)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(1); d < 50*time.Millisecond || d > 150*time.Millisecond {
+			t.Errorf("jittered backoff %v out of range", d)
+		}
+	}
+}
+
+func TestRecoverDuplicates(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     card.Request
+		timeout   bool
+		status    int
+		lookup    int
+		recovered bool
+	}{
+		{"timeout, transaction exists", card.Request{MerchantTransactionID: "order-1"}, true, 0, http.StatusOK, true},
+		{"5xx, transaction exists", card.Request{MerchantTransactionID: "order-2"}, false, http.StatusServiceUnavailable, http.StatusOK, true},
+		{"5xx, transaction missing", card.Request{MerchantTransactionID: "order-3"}, false, http.StatusServiceUnavailable, http.StatusNotFound, false},
+		{"4xx is not ambiguous", card.Request{MerchantTransactionID: "order-4"}, false, http.StatusBadRequest, http.StatusOK, false},
+		{"no merchantTransactionId", card.Request{}, false, http.StatusServiceUnavailable, http.StatusOK, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.WriteHeader(test.lookup)
+					w.Write([]byte(`{"transactionId":"existing"}`))
+					return
+				}
+				if test.timeout {
+					time.Sleep(200 * time.Millisecond)
+					return
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer srv.Close()
+
+			client := srv.Client()
+			client.Timeout = 100 * time.Millisecond
+			c := New(client, srv.URL)
+			c.RecoverDuplicates = true
+
+			resp := card.Response{}
+			err := c.Sale(test.input, &resp, Opts{})
+			if test.recovered {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !resp.Recovered || resp.TransactionID != "existing" {
+					t.Errorf("expected recovered transaction, got %#v", resp)
+				}
+				return
+			}
+			if err == nil {
+				t.Error("expected the original error")
+			}
+			if resp.Recovered {
+				t.Error("response shouldn't be marked as recovered")
+			}
+		})
+	}
+}
+
+func TestRecoverDuplicatesDeadline(t *testing.T) {
+	tests := []struct {
+		name      string
+		cancel    bool
+		recovered bool
+	}{
+		{"deadline expired after commit", false, true},
+		{"canceled", true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var lookups int32
+			committed := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					atomic.AddInt32(&lookups, 1)
+					w.Write([]byte(`{"transactionId":"existing"}`))
+					return
+				}
+				// The transaction is committed, but the answer comes too late.
+				close(committed)
+				time.Sleep(200 * time.Millisecond)
+			}))
+			defer srv.Close()
+
+			c := New(srv.Client(), srv.URL)
+			c.RecoverDuplicates = true
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			if test.cancel {
+				go func() {
+					<-committed
+					cancel()
+				}()
+			}
+
+			resp := card.Response{}
+			err := c.SaleContext(ctx, card.Request{MerchantTransactionID: "order-1"}, &resp, Opts{})
+			n := atomic.LoadInt32(&lookups)
+			if !test.recovered {
+				if !errors.Is(err, context.Canceled) || n != 0 {
+					t.Errorf("expected a canceled call without lookup, got %v and %d lookups", err, n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !resp.Recovered || resp.TransactionID != "existing" || n != 1 {
+				t.Errorf("expected the recovered transaction after 1 lookup, got %#v and %d lookups", resp, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package bluesnap
+
+import (
+	"context"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// StoreCredential makes input the first, shopper-initiated, transaction of a
+// card stored for usage u and returns the stored credential, holding the
+// network transaction ID of the transaction, to charge the card later with
+// ChargeStoredCredential. input is sent as an Auth when its
+// cardTransactionType is AUTH_ONLY and as a Sale otherwise. An error wrapping
+// card.ErrCredentialIncomplete means the transaction succeeded, and must not
+// be retried, but the returned credential is partial.
+func (c Connector) StoreCredential(input card.Request, u card.StoredCredentialUsage, output *card.Response, opts Opts) (card.StoredCredential, error) {
+	return c.StoreCredentialContext(context.Background(), input, u, output, opts)
+}
+
+func (c Connector) StoreCredentialContext(ctx context.Context, input card.Request, u card.StoredCredentialUsage, output *card.Response, opts Opts) (card.StoredCredential, error) {
+	input.SetInitialStoredCredential()
+
+	var err error
+	if input.CardTransactionType == card.TransactionAuthOnly {
+		err = c.AuthContext(ctx, input, output, opts)
+	} else {
+		err = c.SaleContext(ctx, input, output, opts)
+	}
+	if err != nil {
+		return card.StoredCredential{}, err
+	}
+	return output.StoredCredential(u)
+}
+
+// ChargeStoredCredential makes a merchant-initiated sale of amount with a
+// stored credential, see card.StoredCredential.MerchantInitiatedRequest.
+func (c Connector) ChargeStoredCredential(cred card.StoredCredential, amount money.Money, output *card.Response, opts Opts) error {
+	return c.ChargeStoredCredentialContext(context.Background(), cred, amount, output, opts)
+}
+
+func (c Connector) ChargeStoredCredentialContext(ctx context.Context, cred card.StoredCredential, amount money.Money, output *card.Response, opts Opts) error {
+	input, err := cred.MerchantInitiatedRequest(card.TransactionAuthCapture, amount)
+	if err != nil {
+		return err
+	}
+	return c.SaleContext(ctx, input, output, opts)
+}
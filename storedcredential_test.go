@@ -0,0 +1,119 @@
+package bluesnap
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+func TestStoredCredential(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.Write([]byte(`{"transactionId":"1012","vaultedShopperId":19574632,"amount":25,"currency":"USD","cardTransactionType":"AUTH_CAPTURE",
+			"creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},
+			"processingInfo":{"processingStatus":"success","networkTransactionId":"019072416113666"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	c.ValidateRequests = true
+
+	out := card.Response{}
+	cred, err := c.StoreCredential(card.Request{
+		CardTransactionType: card.TransactionAuthCapture,
+		Amount:              "25.00",
+		Currency:            "USD",
+		CreditCard:          &card.CreditCardRequest{CardNumber: "4111111111111111", ExpirationMonth: "07", ExpirationYear: "2030", SecurityCode: "111"},
+	}, card.UsageInstallment, &out, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCred := card.StoredCredential{
+		Usage:                card.UsageInstallment,
+		VaultedShopperID:     19574632,
+		CardLastFourDigits:   "1111",
+		CardType:             card.CardVisa,
+		NetworkTransactionID: "019072416113666",
+	}
+	if cred != expectedCred {
+		t.Errorf("expected %+v, got %+v", expectedCred, cred)
+	}
+
+	for _, u := range []card.StoredCredentialUsage{card.UsageRecurring, card.UsageUnscheduled, card.UsageInstallment} {
+		cred.Usage = u
+		if err := c.ChargeStoredCredential(cred, usd("25.00"), &out, Opts{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		`{"amount":25.00,"currency":"USD","creditCard":{"cardNumber":"4111111111111111","expirationMonth":"07","expirationYear":"2030","securityCode":"111"},"cardTransactionType":"AUTH_CAPTURE","storeCard":true,"transactionInitiator":"SHOPPER","recurringTransaction":"ECOMMERCE","transactionId":""}`,
+		`{"amount":25.00,"vaultedShopperId":19574632,"currency":"USD","creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"cardTransactionType":"AUTH_CAPTURE","networkTransactionInfo":{"originalNetworkTransactionId":"019072416113666"},"transactionInitiator":"MERCHANT","recurringTransaction":"RECURRING","transactionId":""}`,
+		`{"amount":25.00,"vaultedShopperId":19574632,"currency":"USD","creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"cardTransactionType":"AUTH_CAPTURE","networkTransactionInfo":{"originalNetworkTransactionId":"019072416113666"},"transactionInitiator":"MERCHANT","recurringTransaction":"ECOMMERCE","transactionId":""}`,
+		`{"amount":25.00,"vaultedShopperId":19574632,"currency":"USD","creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"cardTransactionType":"AUTH_CAPTURE","networkTransactionInfo":{"originalNetworkTransactionId":"019072416113666"},"transactionInitiator":"MERCHANT","recurringTransaction":"RECURRING","transactionId":""}`,
+	}
+	if len(bodies) != len(expected) {
+		t.Fatalf("expected %d requests, got %d", len(expected), len(bodies))
+	}
+	for i := range expected {
+		if bodies[i] != expected[i] {
+			t.Errorf("request %d: expected\n%s, got\n%s", i, expected[i], bodies[i])
+		}
+	}
+
+	if err := c.ChargeStoredCredential(card.StoredCredential{VaultedShopperID: 19574632}, usd("25.00"), &out, Opts{}); err == nil {
+		t.Error("expected an error without usage")
+	}
+}
+
+func TestStoredCredentialIncomplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		cred     card.StoredCredential
+	}{
+		{"no networkTransactionId", `{"transactionId":"1012","vaultedShopperId":19574632,"creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"processingInfo":{"processingStatus":"success"}}`,
+			card.StoredCredential{Usage: card.UsageRecurring, VaultedShopperID: 19574632, CardLastFourDigits: "1111", CardType: card.CardVisa}},
+		{"no vaulted shopper", `{"transactionId":"1012","creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"processingInfo":{"processingStatus":"success","networkTransactionId":"019072416113666"}}`,
+			card.StoredCredential{Usage: card.UsageRecurring, CardLastFourDigits: "1111", CardType: card.CardVisa, NetworkTransactionID: "019072416113666"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte(test.response))
+			}))
+			defer srv.Close()
+
+			c := New(srv.Client(), srv.URL)
+			out := card.Response{}
+			cred, err := c.StoreCredential(card.Request{
+				CardTransactionType: card.TransactionAuthCapture,
+				Amount:              "25.00",
+				Currency:            "USD",
+				CreditCard:          &card.CreditCardRequest{CardNumber: "4111111111111111", ExpirationMonth: "07", ExpirationYear: "2030", SecurityCode: "111"},
+			}, card.UsageRecurring, &out, Opts{})
+			if !errors.Is(err, card.ErrCredentialIncomplete) {
+				t.Fatalf("expected ErrCredentialIncomplete, got %v", err)
+			}
+			if cred != test.cred {
+				t.Errorf("expected %+v, got %+v", test.cred, cred)
+			}
+			if out.TransactionID != "1012" || requests != 1 {
+				t.Errorf("expected a single successful transaction, got %d requests and %+v", requests, out)
+			}
+		})
+	}
+
+	out := card.Response{TransactionID: "1012", ProcessingInfo: card.ProcessingInfo{ProcessingStatus: "FAILED"}}
+	if _, err := out.StoredCredential(card.UsageRecurring); err == nil || errors.Is(err, card.ErrCredentialIncomplete) {
+		t.Errorf("expected a failed transaction error, got %v", err)
+	}
+}
@@ -9,14 +9,10 @@ type ErrorMessage struct {
 }
 
 type Errors struct {
-	StatusCode int `json:"-"`
-	Messages []ErrorMessage `json:"message"`
+	StatusCode int            `json:"-"`
+	Messages   []ErrorMessage `json:"message"`
 }
 
 func (e Errors) IsEmpty() bool {
 	return len(e.Messages) == 0
 }
-
-func emptyErrors() Errors {
-	return Errors{}
-}
\ No newline at end of file
@@ -0,0 +1,204 @@
+package bluesnap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Tracer starts spans. It mirrors the shape of an OpenTelemetry tracer, see
+// the otelbluesnap module for the adapter.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced call.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key-value pair attached to a Span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attribute keys set by TracingMiddleware.
+const (
+	AttrOperation     = "bluesnap.operation"
+	AttrMethod        = "bluesnap.payment_method"
+	AttrAmount        = "bluesnap.amount"
+	AttrCurrency      = "bluesnap.currency"
+	AttrTransactionID = "bluesnap.transaction_id"
+	AttrStatusCode    = "http.status_code"
+	AttrErrorCode     = "bluesnap.error_code"
+	AttrRecovered     = "bluesnap.recovered"
+)
+
+// TracingMiddleware wraps every call in a span named after its operation,
+// e.g. "bluesnap.Sale", started from the caller's context.
+func TracingMiddleware(tracer Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			ctx, span := tracer.Start(ctx, "bluesnap."+string(req.Operation))
+			defer span.End()
+
+			span.SetAttributes(
+				Attribute{AttrOperation, string(req.Operation)},
+				Attribute{AttrMethod, paymentMethod(req)},
+			)
+			fields := traceFields(req.Body)
+			if fields.TransactionID == "" {
+				fields.TransactionID = endpointTransactionID(req.Endpoint)
+			}
+			if fields.TransactionID != "" {
+				span.SetAttributes(Attribute{AttrTransactionID, fields.TransactionID})
+			}
+			if fields.Amount != nil {
+				span.SetAttributes(Attribute{AttrAmount, fmt.Sprint(fields.Amount)})
+			}
+			if fields.Currency != "" {
+				span.SetAttributes(Attribute{AttrCurrency, fields.Currency})
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				span.RecordError(err)
+				return resp, err
+			}
+
+			span.SetAttributes(Attribute{AttrStatusCode, resp.StatusCode})
+			if resp.Recovered {
+				span.SetAttributes(Attribute{AttrRecovered, true})
+			}
+			if id := traceFields(resp.Body).TransactionID; id != "" {
+				span.SetAttributes(Attribute{AttrTransactionID, id})
+			}
+			if resp.StatusCode > 399 {
+				apiErr := newAPIError(resp.StatusCode, resp.Header, resp.Body)
+				span.SetAttributes(Attribute{AttrErrorCode, errorCode(apiErr)})
+				span.RecordError(apiErr)
+			}
+			return resp, nil
+		}
+	}
+}
+
+type tracedFields struct {
+	Amount        interface{} `json:"amount"`
+	Currency      string      `json:"currency"`
+	TransactionID string      `json:"transactionId"`
+}
+
+// traceFields extracts the span attributes found in a request or response
+// body.
+func traceFields(body []byte) tracedFields {
+	var f tracedFields
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if len(body) == 0 || dec.Decode(&f) != nil {
+		return tracedFields{}
+	}
+	return f
+}
+
+// endpointTransactionID returns the transaction ID found in the path of
+// endpoint, as in "/services/2/transactions/{id}" or
+// "/services/2/transactions/refund/{id}/cancel". Merchant transaction IDs are
+// not returned.
+func endpointTransactionID(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, s := range segments {
+		if s != "transactions" && s != "alt-transactions" {
+			continue
+		}
+		rest := segments[i+1:]
+		if len(rest) > 0 && rest[0] == "refund" {
+			rest = rest[1:]
+		}
+		if len(rest) == 0 || rest[0] == "merchant" {
+			return ""
+		}
+		id, err := url.PathUnescape(rest[0])
+		if err != nil {
+			return ""
+		}
+		return id
+	}
+	return ""
+}
+
+// SpanRecorder is an in-memory Tracer keeping every span it started, meant
+// for tests.
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span started by a SpanRecorder.
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+
+	mu *sync.Mutex
+}
+
+type spanContextKey struct{}
+
+func (r *SpanRecorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &RecordedSpan{
+		Name:       name,
+		Attributes: map[string]interface{}{},
+		mu:         &r.mu,
+	}
+	s.Parent, _ = ctx.Value(spanContextKey{}).(*RecordedSpan)
+	r.spans = append(r.spans, s)
+	return context.WithValue(ctx, spanContextKey{}, s), s
+}
+
+// Spans returns the spans started so far.
+func (r *SpanRecorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*RecordedSpan(nil), r.spans...)
+}
+
+func (s *RecordedSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range attrs {
+		s.Attributes[a.Key] = a.Value
+	}
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Ended = true
+}
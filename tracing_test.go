@@ -0,0 +1,108 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := &SpanRecorder{}
+	fake := func(next Handler) Handler {
+		return func(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+			if s, _ := ctx.Value(spanContextKey{}).(*RecordedSpan); s == nil || s.Name != "bluesnap."+string(req.Operation) {
+				t.Error("span should be propagated to the next handler")
+			}
+			switch req.Operation {
+			case OpAuth:
+				return &APIResponse{StatusCode: http.StatusBadRequest, Body: []byte(`{"message":[{"code":14002,"errorName":"EXPIRED_CARD"}]}`)}, nil
+			case OpRetrieve:
+				return nil, errors.New("connection reset")
+			}
+			return &APIResponse{StatusCode: http.StatusOK, Body: []byte(`{"transactionId":"1035511869","amount":11.5,"currency":"USD"}`)}, nil
+		}
+	}
+
+	c := New(nil, testURL)
+	c.Middleware = []Middleware{TracingMiddleware(recorder), fake}
+
+	ctx, parent := recorder.Start(context.Background(), "checkout")
+	c.SaleContext(ctx, card.Request{Amount: "11.50", Currency: "USD"}, &card.Response{}, Opts{})
+	c.AuthContext(ctx, card.Request{Amount: "11", Currency: "EUR"}, &card.Response{}, Opts{})
+	c.RetrieveContext(ctx, "1035511869", &card.Response{}, Opts{})
+	parent.End()
+
+	spans := recorder.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+
+	tests := []struct {
+		span       *RecordedSpan
+		name       string
+		attributes map[string]interface{}
+		errors     int
+	}{
+		{spans[1], "bluesnap.Sale", map[string]interface{}{
+			AttrOperation:     "Sale",
+			AttrMethod:        card.Method,
+			AttrAmount:        "11.50",
+			AttrCurrency:      "USD",
+			AttrStatusCode:    200,
+			AttrTransactionID: "1035511869",
+		}, 0},
+		{spans[2], "bluesnap.Auth", map[string]interface{}{
+			AttrOperation:  "Auth",
+			AttrAmount:     "11",
+			AttrCurrency:   "EUR",
+			AttrStatusCode: 400,
+			AttrErrorCode:  "EXPIRED_CARD",
+		}, 1},
+		{spans[3], "bluesnap.Retrieve", map[string]interface{}{
+			AttrOperation:     "Retrieve",
+			AttrMethod:        card.Method,
+			AttrTransactionID: "1035511869",
+		}, 1},
+	}
+	for _, test := range tests {
+		if test.span.Name != test.name {
+			t.Errorf("span should be %s, instead of %s", test.name, test.span.Name)
+		}
+		if test.span.Parent != spans[0] {
+			t.Errorf("%s: parent should be the caller's span", test.name)
+		}
+		if !test.span.Ended {
+			t.Errorf("%s: span should be ended", test.name)
+		}
+		if len(test.span.Errors) != test.errors {
+			t.Errorf("%s: expected %d errors, got %v", test.name, test.errors, test.span.Errors)
+		}
+		for k, v := range test.attributes {
+			if test.span.Attributes[k] != v {
+				t.Errorf("%s: %s should be %v, instead of %v", test.name, k, v, test.span.Attributes[k])
+			}
+		}
+	}
+}
+
+func TestEndpointTransactionID(t *testing.T) {
+	tests := map[string]string{
+		"/services/2/transactions":                       "",
+		"/services/2/transactions/1035511869":            "1035511869",
+		"/services/2/transactions/merchant/order-1":      "",
+		"/services/2/transactions/refund/1035511869":     "1035511869",
+		"/services/2/transactions/refund/merchant/o%2F1": "",
+		"/services/2/transactions/refund/1041/cancel":    "1041",
+		"/services/2/alt-transactions/38490":             "38490",
+		"/services/2/vaulted-shoppers/19549018":          "",
+		"/services/2/transactions/1035511869?fields=all": "1035511869",
+	}
+	for endpoint, expected := range tests {
+		if id := endpointTransactionID(endpoint); id != expected {
+			t.Errorf("%s: expected %q, got %q", endpoint, expected, id)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package bluesnap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/vaultedshopper"
+)
+
+// AddVaultedShopperCard stores a card, with raw or encrypted number and
+// security code, on a shopper. output receives the updated shopper.
+func (c Connector) AddVaultedShopperCard(vaultedShopperID string, cc card.CreditCardRequest, billing *card.BillingContactInfo, output *vaultedshopper.Response, opts Opts) error {
+	return c.AddVaultedShopperCardContext(context.Background(), vaultedShopperID, cc, billing, output, opts)
+}
+
+func (c Connector) AddVaultedShopperCardContext(ctx context.Context, vaultedShopperID string, cc card.CreditCardRequest, billing *card.BillingContactInfo, output *vaultedshopper.Response, opts Opts) error {
+	return c.UpdateVaultedShopperContext(ctx, vaultedShopperID, vaultedshopper.AddCard(cc, billing), output, opts)
+}
+
+// AddVaultedShopperCardToken stores the card entered in Hosted Payment Fields
+// under pfToken on a shopper.
+func (c Connector) AddVaultedShopperCardToken(vaultedShopperID, pfToken string, output *vaultedshopper.Response, opts Opts) error {
+	return c.AddVaultedShopperCardTokenContext(context.Background(), vaultedShopperID, pfToken, output, opts)
+}
+
+func (c Connector) AddVaultedShopperCardTokenContext(ctx context.Context, vaultedShopperID, pfToken string, output *vaultedshopper.Response, opts Opts) error {
+	return c.UpdateVaultedShopperContext(ctx, vaultedShopperID, vaultedshopper.AddCardToken(pfToken), output, opts)
+}
+
+// RemoveVaultedShopperCard removes the stored card with the given last four
+// digits and type from a shopper.
+func (c Connector) RemoveVaultedShopperCard(vaultedShopperID, lastFourDigits string, cardType card.CardType, output *vaultedshopper.Response, opts Opts) error {
+	return c.RemoveVaultedShopperCardContext(context.Background(), vaultedShopperID, lastFourDigits, cardType, output, opts)
+}
+
+func (c Connector) RemoveVaultedShopperCardContext(ctx context.Context, vaultedShopperID, lastFourDigits string, cardType card.CardType, output *vaultedshopper.Response, opts Opts) error {
+	return c.UpdateVaultedShopperContext(ctx, vaultedShopperID, vaultedshopper.RemoveCard(lastFourDigits, cardType), output, opts)
+}
+
+// SetDefaultVaultedShopperCard makes a stored card the default payment source
+// of a shopper.
+func (c Connector) SetDefaultVaultedShopperCard(vaultedShopperID, lastFourDigits string, cardType card.CardType, output *vaultedshopper.Response, opts Opts) error {
+	return c.SetDefaultVaultedShopperCardContext(context.Background(), vaultedShopperID, lastFourDigits, cardType, output, opts)
+}
+
+func (c Connector) SetDefaultVaultedShopperCardContext(ctx context.Context, vaultedShopperID, lastFourDigits string, cardType card.CardType, output *vaultedshopper.Response, opts Opts) error {
+	return c.UpdateVaultedShopperContext(ctx, vaultedShopperID, vaultedshopper.SetDefaultCard(lastFourDigits, cardType), output, opts)
+}
+
+// ListVaultedShopperSources retrieves a shopper and returns its payment
+// sources, see vaultedshopper.Response.Sources.
+func (c Connector) ListVaultedShopperSources(vaultedShopperID string, opts Opts) ([]vaultedshopper.Source, error) {
+	return c.ListVaultedShopperSourcesContext(context.Background(), vaultedShopperID, opts)
+}
+
+func (c Connector) ListVaultedShopperSourcesContext(ctx context.Context, vaultedShopperID string, opts Opts) ([]vaultedshopper.Source, error) {
+	shopper := vaultedshopper.Response{}
+	if err := c.RetrieveVaultedShopperContext(ctx, vaultedShopperID, &shopper, opts); err != nil {
+		return nil, err
+	}
+	return shopper.Sources(), nil
+}
+
+// ChargeVaulted makes a sale with a stored payment source of a shopper: a
+// card is charged with AUTH_CAPTURE and a bank account through ECP. When
+// charge.Source is the default one, the shopper is retrieved first to find
+// it. output is a *card.Response or an *ecp.Response, matching the source.
+// Bank accounts are only debited with charge.AuthorizedByShopper set.
+func (c Connector) ChargeVaulted(charge vaultedshopper.Charge, output Deserializer, opts Opts) error {
+	return c.ChargeVaultedContext(context.Background(), charge, output, opts)
+}
+
+func (c Connector) ChargeVaultedContext(ctx context.Context, charge vaultedshopper.Charge, output Deserializer, opts Opts) error {
+	charge, err := c.resolveVaultedSource(ctx, charge, opts)
+	if err != nil {
+		return err
+	}
+
+	switch charge.Source.Type {
+	case vaultedshopper.SourceCard:
+		input, err := charge.CardRequest(card.TransactionAuthCapture)
+		if err != nil {
+			return err
+		}
+		return c.SaleContext(ctx, input, output, opts)
+	case vaultedshopper.SourceECP:
+		input, err := charge.ECPRequest()
+		if err != nil {
+			return err
+		}
+		return c.SaleContext(ctx, input, output, opts)
+	}
+
+	return fmt.Errorf("cannot charge %s", charge.Source)
+}
+
+// AuthVaulted authorizes a stored card of a shopper with AUTH_ONLY, see
+// ChargeVaulted. Bank accounts cannot be authorized.
+func (c Connector) AuthVaulted(charge vaultedshopper.Charge, output *card.Response, opts Opts) error {
+	return c.AuthVaultedContext(context.Background(), charge, output, opts)
+}
+
+func (c Connector) AuthVaultedContext(ctx context.Context, charge vaultedshopper.Charge, output *card.Response, opts Opts) error {
+	charge, err := c.resolveVaultedSource(ctx, charge, opts)
+	if err != nil {
+		return err
+	}
+	input, err := charge.CardRequest(card.TransactionAuthOnly)
+	if err != nil {
+		return err
+	}
+	return c.AuthContext(ctx, input, output, opts)
+}
+
+// resolveVaultedSource replaces the default source of charge by the stored
+// source it designates.
+func (c Connector) resolveVaultedSource(ctx context.Context, charge vaultedshopper.Charge, opts Opts) (vaultedshopper.Charge, error) {
+	if !charge.Source.IsDefault() {
+		return charge, nil
+	}
+	if charge.VaultedShopperID == 0 {
+		return charge, errors.New("charge has no vaultedShopperId")
+	}
+
+	shopper := vaultedshopper.Response{}
+	if err := c.RetrieveVaultedShopperContext(ctx, strconv.FormatInt(charge.VaultedShopperID, 10), &shopper, opts); err != nil {
+		return charge, err
+	}
+	source, ok := shopper.DefaultSource()
+	if !ok {
+		return charge, fmt.Errorf("vaulted shopper %d has no default payment source", charge.VaultedShopperID)
+	}
+	charge.Source = source.Selector()
+	return charge, nil
+}
@@ -0,0 +1,190 @@
+package vaultedshopper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/ecp"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// Selector picks the payment source of a shopper to charge. The zero value
+// selects the default source, see Response.DefaultSource.
+type Selector struct {
+	Type                SourceType
+	CardLastFourDigits  string
+	CardType            card.CardType
+	PublicAccountNumber string
+	PublicRoutingNumber string
+}
+
+// DefaultSource selects the default payment source of a shopper.
+func DefaultSource() Selector {
+	return Selector{}
+}
+
+// CardSource selects a stored card by its last four digits and type.
+func CardSource(lastFourDigits string, cardType card.CardType) Selector {
+	return Selector{Type: SourceCard, CardLastFourDigits: lastFourDigits, CardType: cardType}
+}
+
+// ECPSource selects a stored bank account by its public account and routing
+// numbers, e.g. "xxxx0000" and "xxxxx1234".
+func ECPSource(publicAccountNumber, publicRoutingNumber string) Selector {
+	return Selector{Type: SourceECP, PublicAccountNumber: publicAccountNumber, PublicRoutingNumber: publicRoutingNumber}
+}
+
+func (s Selector) IsDefault() bool {
+	return s.Type == ""
+}
+
+func (s Selector) String() string {
+	switch s.Type {
+	case "":
+		return "default source"
+	case SourceCard:
+		return fmt.Sprintf("%s card ending in %s", s.CardType, s.CardLastFourDigits)
+	case SourceECP:
+		return "bank account " + s.PublicAccountNumber
+	}
+	return string(s.Type) + " source"
+}
+
+// Selector returns the selector of s.
+func (s Source) Selector() Selector {
+	switch {
+	case s.Type == SourceCard && s.CreditCard != nil:
+		return CardSource(s.CreditCard.CardLastFourDigits, s.CreditCard.CardType)
+	case s.Type == SourceECP && s.ECP != nil:
+		return ECPSource(s.ECP.PublicAccountNumber, s.ECP.PublicRoutingNumber)
+	}
+	return Selector{Type: s.Type}
+}
+
+// DefaultSource returns the payment source charged by default: the one set
+// in LastPaymentInfo, or else the only stored source.
+func (r Response) DefaultSource() (Source, bool) {
+	sources := r.Sources()
+	for _, s := range sources {
+		if s.Default {
+			return s, true
+		}
+	}
+	if len(sources) == 1 {
+		return sources[0], true
+	}
+	return Source{}, false
+}
+
+// Charge describes a payment made with a stored payment source, built into
+// the request of its payment method by CardRequest or ECPRequest.
+type Charge struct {
+	VaultedShopperID      int64
+	Source                Selector
+	Amount                money.Money
+	MerchantTransactionID string
+	SoftDescriptor        string
+	// MerchantInitiated marks a charge made without the shopper taking part,
+	// e.g. the retry of a declined renewal, so that the stored credential is
+	// used as a merchant-initiated transaction.
+	MerchantInitiated bool
+	// Usage is the agreement the card was stored under, required by
+	// merchant-initiated charges.
+	Usage card.StoredCredentialUsage
+	// NetworkTransactionID is the network transaction ID of the first
+	// transaction of the stored card, referenced by merchant-initiated
+	// charges, see card.StoredCredential.
+	NetworkTransactionID string
+	// AuthorizedByShopper states that the shopper authorized debiting the
+	// bank account for this charge, which ECPRequest requires.
+	AuthorizedByShopper bool
+}
+
+func (c Charge) check() error {
+	if c.VaultedShopperID == 0 {
+		return errors.New("vaultedshopper: charge has no vaultedShopperId")
+	}
+	if c.Amount.Sign() <= 0 {
+		return fmt.Errorf("vaultedshopper: invalid charge amount %s", c.Amount)
+	}
+	return nil
+}
+
+// CardRequest returns the Sale or Auth request charging the stored card
+// selected by c.Source, t being AUTH_CAPTURE or AUTH_ONLY. Only the last four
+// digits and type of the card are sent, BlueSnap looking up the rest.
+// Merchant-initiated charges are built by
+// card.StoredCredential.MerchantInitiatedRequest.
+func (c Charge) CardRequest(t card.TransactionType) (card.Request, error) {
+	if err := c.check(); err != nil {
+		return card.Request{}, err
+	}
+	if c.Source.Type != SourceCard {
+		return card.Request{}, fmt.Errorf("vaultedshopper: %s is not a card", c.Source)
+	}
+	if c.Source.CardLastFourDigits == "" || c.Source.CardType == "" {
+		return card.Request{}, errors.New("vaultedshopper: card source requires the last four digits and card type")
+	}
+
+	if c.MerchantInitiated {
+		cred := card.StoredCredential{
+			Usage:                c.Usage,
+			VaultedShopperID:     c.VaultedShopperID,
+			CardLastFourDigits:   c.Source.CardLastFourDigits,
+			CardType:             c.Source.CardType,
+			NetworkTransactionID: c.NetworkTransactionID,
+		}
+		r, err := cred.MerchantInitiatedRequest(t, c.Amount)
+		if err != nil {
+			return card.Request{}, err
+		}
+		r.MerchantTransactionID = c.MerchantTransactionID
+		r.SoftDescriptor = c.SoftDescriptor
+		return r, nil
+	}
+
+	r := card.Request{
+		CardTransactionType:   t,
+		VaultedShopperID:      c.VaultedShopperID,
+		MerchantTransactionID: c.MerchantTransactionID,
+		SoftDescriptor:        c.SoftDescriptor,
+		CreditCard: &card.CreditCardRequest{
+			CardLastFourDigits: c.Source.CardLastFourDigits,
+			CardType:           c.Source.CardType,
+		},
+	}
+	r.SetMoney(c.Amount)
+	return r, nil
+}
+
+// ECPRequest returns the Sale request debiting the stored bank account
+// selected by c.Source. The shopper must have authorized the debit, see
+// Charge.AuthorizedByShopper.
+func (c Charge) ECPRequest() (ecp.Request, error) {
+	if err := c.check(); err != nil {
+		return ecp.Request{}, err
+	}
+	if c.Source.Type != SourceECP {
+		return ecp.Request{}, fmt.Errorf("vaultedshopper: %s is not a bank account", c.Source)
+	}
+	if c.Source.PublicAccountNumber == "" || c.Source.PublicRoutingNumber == "" {
+		return ecp.Request{}, errors.New("vaultedshopper: bank account source requires the public account and routing numbers")
+	}
+	if !c.AuthorizedByShopper {
+		return ecp.Request{}, errors.New("vaultedshopper: bank account charge is not authorized by the shopper")
+	}
+
+	return ecp.Request{
+		Amount:                money.Decimal(c.Amount.Amount()),
+		Currency:              string(c.Amount.Currency()),
+		VaultedShopperID:      c.VaultedShopperID,
+		MerchantTransactionID: c.MerchantTransactionID,
+		SoftDescriptor:        c.SoftDescriptor,
+		ECPTransaction: &ecp.Transaction{
+			PublicAccountNumber: c.Source.PublicAccountNumber,
+			PublicRoutingNumber: c.Source.PublicRoutingNumber,
+		},
+		AuthorizedByShopper: c.AuthorizedByShopper,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package vaultedshopper
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+)
+
+const Method = "vaulted-shopper"
+
+func (r Request) ToJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r Request) Method() string {
+	return Method
+}
+
+func (r *Response) FromJSON(data []byte) error {
+	return json.Unmarshal(data, r)
+}
+
+// FromHeader reads the Location header of a created shopper, taking its ID
+// from the URL when the body does not hold it.
+func (r *Response) FromHeader(h http.Header) error {
+	r.Location = h.Get("Location")
+	if r.VaultedShopperID == 0 && r.Location != "" {
+		if id, err := strconv.ParseInt(path.Base(r.Location), 10, 64); err == nil {
+			r.VaultedShopperID = id
+		}
+	}
+	return nil
+}
+
+func (r Response) Method() string {
+	return Method
+}
@@ -0,0 +1,124 @@
+package vaultedshopper
+
+import "github.com/metricsglobal/bluesnap/card"
+
+// AddCard returns the update adding cc, with raw or encrypted card number
+// and security code, to a shopper.
+func AddCard(cc card.CreditCardRequest, billing *card.BillingContactInfo) Request {
+	return Request{PaymentSources: &PaymentSources{
+		CreditCardInfo: []CreditCardInfo{{CreditCard: &cc, BillingContactInfo: billing}},
+	}}
+}
+
+// AddCardToken returns the update adding the card entered in Hosted Payment
+// Fields under pfToken to a shopper.
+func AddCardToken(pfToken string) Request {
+	return Request{PFToken: pfToken}
+}
+
+// RemoveCard returns the update removing the stored card identified by its
+// last four digits and type.
+func RemoveCard(lastFourDigits string, cardType card.CardType) Request {
+	return Request{PaymentSources: &PaymentSources{
+		CreditCardInfo: []CreditCardInfo{{
+			CreditCard: &card.CreditCardRequest{CardLastFourDigits: lastFourDigits, CardType: cardType},
+			Status:     statusDeleted,
+		}},
+	}}
+}
+
+// SetDefaultCard returns the update making a stored card the default payment
+// source.
+func SetDefaultCard(lastFourDigits string, cardType card.CardType) Request {
+	return Request{LastPaymentInfo: &LastPaymentInfo{
+		PaymentMethod: SourceCard,
+		CreditCard:    &card.CreditCardResponse{CardLastFourDigits: lastFourDigits, CardType: cardType},
+	}}
+}
+
+// SetDefaultECP returns the update making a stored bank account, identified
+// by its public account and routing numbers, the default payment source.
+func SetDefaultECP(publicAccountNumber, publicRoutingNumber string) Request {
+	return Request{LastPaymentInfo: &LastPaymentInfo{
+		PaymentMethod: SourceECP,
+		ECP:           &ECP{PublicAccountNumber: publicAccountNumber, PublicRoutingNumber: publicRoutingNumber},
+	}}
+}
+
+// Source is a stored payment source of a shopper, only the field matching
+// Type being set.
+type Source struct {
+	Type               SourceType
+	CreditCard         *card.CreditCardResponse
+	ECP                *ECP
+	SEPADirectDebit    *SEPADirectDebit
+	BillingContactInfo *card.BillingContactInfo
+	// Default is set on the source matching LastPaymentInfo.
+	Default bool
+}
+
+// Sources lists the payment sources of r: cards, then bank accounts, then
+// SEPA mandates.
+func (r Response) Sources() []Source {
+	var sources []Source
+	for i := range r.PaymentSources.CreditCardInfo {
+		info := &r.PaymentSources.CreditCardInfo[i]
+		sources = append(sources, Source{
+			Type:               SourceCard,
+			CreditCard:         &info.CreditCard,
+			BillingContactInfo: info.BillingContactInfo,
+			Default:            r.isDefaultCard(info.CreditCard),
+		})
+	}
+	for i := range r.PaymentSources.ECPDetails {
+		d := &r.PaymentSources.ECPDetails[i]
+		sources = append(sources, Source{
+			Type:               SourceECP,
+			ECP:                &d.ECP,
+			BillingContactInfo: d.BillingContactInfo,
+			Default: r.LastPaymentInfo != nil && r.LastPaymentInfo.PaymentMethod == SourceECP && r.LastPaymentInfo.ECP != nil &&
+				r.LastPaymentInfo.ECP.PublicAccountNumber == d.ECP.PublicAccountNumber &&
+				r.LastPaymentInfo.ECP.PublicRoutingNumber == d.ECP.PublicRoutingNumber,
+		})
+	}
+	for i := range r.PaymentSources.SEPADirectDebitInfo {
+		d := &r.PaymentSources.SEPADirectDebitInfo[i]
+		sources = append(sources, Source{
+			Type:               SourceSEPADirectDebit,
+			SEPADirectDebit:    &d.SEPADirectDebit,
+			BillingContactInfo: d.BillingContactInfo,
+			Default:            r.isDefaultSEPADirectDebit(d.SEPADirectDebit),
+		})
+	}
+	return sources
+}
+
+func (r Response) isDefaultCard(cc card.CreditCardResponse) bool {
+	l := r.LastPaymentInfo
+	return l != nil && l.PaymentMethod == SourceCard && l.CreditCard != nil &&
+		l.CreditCard.CardLastFourDigits == cc.CardLastFourDigits && l.CreditCard.CardType == cc.CardType
+}
+
+// isDefaultSEPADirectDebit matches mandates by id, or by the IBAN digits
+// when LastPaymentInfo has no mandate id.
+func (r Response) isDefaultSEPADirectDebit(d SEPADirectDebit) bool {
+	l := r.LastPaymentInfo
+	if l == nil || l.PaymentMethod != SourceSEPADirectDebit || l.SEPADirectDebit == nil {
+		return false
+	}
+	if l.SEPADirectDebit.MandateID != "" {
+		return l.SEPADirectDebit.MandateID == d.MandateID
+	}
+	return l.SEPADirectDebit.IBANFirstFour != "" && l.SEPADirectDebit.IBANLastFour != "" &&
+		l.SEPADirectDebit.IBANFirstFour == d.IBANFirstFour && l.SEPADirectDebit.IBANLastFour == d.IBANLastFour
+}
+
+// Card returns the stored card with the given last four digits and type.
+func (r Response) Card(lastFourDigits string, cardType card.CardType) (CreditCardInfoResponse, bool) {
+	for _, info := range r.PaymentSources.CreditCardInfo {
+		if info.CreditCard.CardLastFourDigits == lastFourDigits && info.CreditCard.CardType == cardType {
+			return info, true
+		}
+	}
+	return CreditCardInfoResponse{}, false
+}
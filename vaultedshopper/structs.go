@@ -0,0 +1,139 @@
+package vaultedshopper
+
+import "github.com/metricsglobal/bluesnap/card"
+
+// Request creates or updates a vaulted shopper. On update, only the fields
+// that are set are changed and payment sources are added to the existing
+// ones.
+type Request struct {
+	FirstName                    string                            `json:"firstName,omitempty"`
+	LastName                     string                            `json:"lastName,omitempty"`
+	Email                        string                            `json:"email,omitempty"`
+	Country                      string                            `json:"country,omitempty"`
+	State                        string                            `json:"state,omitempty"`
+	Address                      string                            `json:"address,omitempty"`
+	Address2                     string                            `json:"address2,omitempty"`
+	City                         string                            `json:"city,omitempty"`
+	Zip                          string                            `json:"zip,omitempty"`
+	Phone                        string                            `json:"phone,omitempty"`
+	CompanyName                  string                            `json:"companyName,omitempty"`
+	PersonalIdentificationNumber string                            `json:"personalIdentificationNumber,omitempty"`
+	MerchantShopperID            string                            `json:"merchantShopperId,omitempty"`
+	ShopperCurrency              string                            `json:"shopperCurrency,omitempty"`
+	SoftDescriptor               string                            `json:"softDescriptor,omitempty"`
+	DescriptorPhoneNumber        string                            `json:"descriptorPhoneNumber,omitempty"`
+	PaymentSources               *PaymentSources                   `json:"paymentSources,omitempty"`
+	ShippingContactInfo          *card.ShippingContactInfo         `json:"shippingContactInfo,omitempty"`
+	TransactionFraudInfo         *card.TransactionFraudInfoRequest `json:"transactionFraudInfo,omitempty"`
+	ThreeDSecure                 *card.ThreeDSecureRequest         `json:"threeDSecure,omitempty"`
+	// PFToken adds the card entered in Hosted Payment Fields.
+	PFToken string `json:"pfToken,omitempty"`
+	// LastPaymentInfo sets the default payment source.
+	LastPaymentInfo *LastPaymentInfo `json:"lastPaymentInfo,omitempty"`
+}
+
+type PaymentSources struct {
+	CreditCardInfo      []CreditCardInfo      `json:"creditCardInfo,omitempty"`
+	ECPDetails          []ECPDetails          `json:"ecpDetails,omitempty"`
+	SEPADirectDebitInfo []SEPADirectDebitInfo `json:"sepaDirectDebitInfo,omitempty"`
+}
+
+type CreditCardInfo struct {
+	CreditCard         *card.CreditCardRequest  `json:"creditCard,omitempty"`
+	BillingContactInfo *card.BillingContactInfo `json:"billingContactInfo,omitempty"`
+	PFToken            string                   `json:"pfToken,omitempty"`
+	Status             string                   `json:"status,omitempty"`
+}
+
+// ECPDetails is a US bank account (Electronic Check Processing).
+type ECPDetails struct {
+	ECP                ECP                      `json:"ecp"`
+	BillingContactInfo *card.BillingContactInfo `json:"billingContactInfo,omitempty"`
+	Status             string                   `json:"status,omitempty"`
+}
+
+// ECP request and response struct, responses only holding the public
+// account and routing numbers.
+type ECP struct {
+	AccountNumber       string `json:"accountNumber,omitempty"`
+	RoutingNumber       string `json:"routingNumber,omitempty"`
+	AccountType         string `json:"accountType,omitempty"`
+	PublicAccountNumber string `json:"publicAccountNumber,omitempty"`
+	PublicRoutingNumber string `json:"publicRoutingNumber,omitempty"`
+}
+
+type SEPADirectDebitInfo struct {
+	SEPADirectDebit    SEPADirectDebit          `json:"sepaDirectDebit"`
+	BillingContactInfo *card.BillingContactInfo `json:"billingContactInfo,omitempty"`
+	Status             string                   `json:"status,omitempty"`
+}
+
+// SEPADirectDebit request and response struct, responses only holding the
+// first and last four characters of the IBAN.
+type SEPADirectDebit struct {
+	IBAN          string `json:"iban,omitempty"`
+	IBANFirstFour string `json:"ibanFirstFour,omitempty"`
+	IBANLastFour  string `json:"ibanLastFour,omitempty"`
+	MandateID     string `json:"mandateId,omitempty"`
+	MandateDate   string `json:"mandateDate,omitempty"`
+}
+
+type Response struct {
+	VaultedShopperID             int64                     `json:"vaultedShopperId"`
+	FirstName                    string                    `json:"firstName"`
+	LastName                     string                    `json:"lastName"`
+	Email                        string                    `json:"email"`
+	Country                      string                    `json:"country"`
+	State                        string                    `json:"state"`
+	Address                      string                    `json:"address"`
+	Address2                     string                    `json:"address2"`
+	City                         string                    `json:"city"`
+	Zip                          string                    `json:"zip"`
+	Phone                        string                    `json:"phone"`
+	CompanyName                  string                    `json:"companyName"`
+	PersonalIdentificationNumber string                    `json:"personalIdentificationNumber"`
+	MerchantShopperID            string                    `json:"merchantShopperId"`
+	ShopperCurrency              string                    `json:"shopperCurrency"`
+	SoftDescriptor               string                    `json:"softDescriptor"`
+	DescriptorPhoneNumber        string                    `json:"descriptorPhoneNumber"`
+	PaymentSources               PaymentSourcesResponse    `json:"paymentSources"`
+	ShippingContactInfo          *card.ShippingContactInfo `json:"shippingContactInfo,omitempty"`
+	ThreeDSecure                 card.ThreeDSecureResponse `json:"threeDSecure"`
+	FraudResultInfo              card.FraudResultInfo      `json:"fraudResultInfo"`
+	LastPaymentInfo              *LastPaymentInfo          `json:"lastPaymentInfo,omitempty"`
+	// Location is the URL of the shopper, set when it was just created.
+	Location string `json:"-"`
+}
+
+type PaymentSourcesResponse struct {
+	CreditCardInfo      []CreditCardInfoResponse `json:"creditCardInfo"`
+	ECPDetails          []ECPDetails             `json:"ecpDetails"`
+	SEPADirectDebitInfo []SEPADirectDebitInfo    `json:"sepaDirectDebitInfo"`
+}
+
+type CreditCardInfoResponse struct {
+	CreditCard         card.CreditCardResponse  `json:"creditCard"`
+	BillingContactInfo *card.BillingContactInfo `json:"billingContactInfo,omitempty"`
+	ProcessingInfo     *card.ProcessingInfo     `json:"processingInfo,omitempty"`
+}
+
+// LastPaymentInfo is the default payment source of a shopper, the last one
+// charged unless set otherwise.
+type LastPaymentInfo struct {
+	PaymentMethod   SourceType               `json:"paymentMethod"`
+	CreditCard      *card.CreditCardResponse `json:"creditCard,omitempty"`
+	ECP             *ECP                     `json:"ecp,omitempty"`
+	SEPADirectDebit *SEPADirectDebit         `json:"sepaDirectDebit,omitempty"`
+}
+
+// SourceType is the kind of a payment source.
+type SourceType string
+
+const (
+	SourceCard            SourceType = "CC"
+	SourceECP             SourceType = "ECP"
+	SourceSEPADirectDebit SourceType = "SEPA_DIRECT_DEBIT"
+)
+
+// statusDeleted removes the payment source it is set on.
+const statusDeleted = "D"
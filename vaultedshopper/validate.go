@@ -0,0 +1,121 @@
+package vaultedshopper
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/ecp"
+	"github.com/metricsglobal/bluesnap/money"
+)
+
+// Validate checks r before a shopper is created or updated: currency,
+// country and state codes, and every payment source added or removed. It
+// returns a *card.ValidationError listing every invalid field.
+func (r Request) Validate() error {
+	e := &card.ValidationError{}
+	if r.ShopperCurrency != "" && !money.Currency(r.ShopperCurrency).Valid() {
+		e.Add("shopperCurrency", "unknown ISO 4217 currency code "+strconv.Quote(r.ShopperCurrency))
+	}
+	e.Merge("", card.BillingContactInfo{Country: r.Country, State: r.State}.Validate())
+	if r.ShippingContactInfo != nil {
+		e.Merge("shippingContactInfo", r.ShippingContactInfo.Validate())
+	}
+
+	if s := r.PaymentSources; s != nil {
+		for i, info := range s.CreditCardInfo {
+			info.validate(e, "paymentSources.creditCardInfo["+strconv.Itoa(i)+"]")
+		}
+		for i, d := range s.ECPDetails {
+			path := "paymentSources.ecpDetails[" + strconv.Itoa(i) + "]"
+			e.Merge(path+".ecp", ecp.Transaction(d.ECP).Validate())
+			if d.BillingContactInfo != nil {
+				e.Merge(path+".billingContactInfo", d.BillingContactInfo.Validate())
+			}
+		}
+		for i, d := range s.SEPADirectDebitInfo {
+			path := "paymentSources.sepaDirectDebitInfo[" + strconv.Itoa(i) + "]"
+			if d.Status != statusDeleted && !validIBAN(d.SEPADirectDebit.IBAN) {
+				e.Add(path+".sepaDirectDebit.iban", "is not a valid IBAN")
+			}
+			if d.BillingContactInfo != nil {
+				e.Merge(path+".billingContactInfo", d.BillingContactInfo.Validate())
+			}
+		}
+	}
+
+	if l := r.LastPaymentInfo; l != nil {
+		switch {
+		case l.PaymentMethod == SourceCard && l.CreditCard == nil:
+			e.Add("lastPaymentInfo.creditCard", "is required")
+		case l.PaymentMethod == SourceECP && l.ECP == nil:
+			e.Add("lastPaymentInfo.ecp", "is required")
+		case l.PaymentMethod == SourceSEPADirectDebit && l.SEPADirectDebit == nil:
+			e.Add("lastPaymentInfo.sepaDirectDebit", "is required")
+		case l.PaymentMethod != SourceCard && l.PaymentMethod != SourceECP && l.PaymentMethod != SourceSEPADirectDebit:
+			e.Add("lastPaymentInfo.paymentMethod", "must be CC, ECP or SEPA_DIRECT_DEBIT")
+		}
+	}
+	return e.Err()
+}
+
+// validate checks a card to add, raw or as a Hosted Payment Fields token, or
+// the last four digits and type of a card to remove.
+func (info CreditCardInfo) validate(e *card.ValidationError, path string) {
+	if info.Status == statusDeleted {
+		if info.CreditCard == nil || info.CreditCard.CardLastFourDigits == "" || info.CreditCard.CardType == "" {
+			e.Add(path+".creditCard", "cardLastFourDigits and cardType are required to remove a card")
+		}
+		return
+	}
+	switch {
+	case info.CreditCard == nil && info.PFToken == "":
+		e.Add(path+".creditCard", "one of creditCard or pfToken is required")
+	case info.CreditCard != nil && info.PFToken != "":
+		e.Add(path+".pfToken", "cannot be combined with creditCard")
+	case info.CreditCard != nil:
+		e.Merge(path+".creditCard", info.CreditCard.Validate())
+	}
+	if info.BillingContactInfo != nil {
+		e.Merge(path+".billingContactInfo", info.BillingContactInfo.Validate())
+	}
+}
+
+// validIBAN reports whether iban, spaces aside, has a country code, check
+// digits and the ISO 13616 mod 97 remainder of 1.
+func validIBAN(iban string) bool {
+	iban = strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(iban) < 15 || len(iban) > 34 || !letters(iban[:2]) || !digits(iban[2:4]) {
+		return false
+	}
+	mod := 0
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			mod = (mod*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			mod = (mod*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return mod == 1
+}
+
+func letters(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
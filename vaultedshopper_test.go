@@ -0,0 +1,312 @@
+package bluesnap
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metricsglobal/bluesnap/card"
+	"github.com/metricsglobal/bluesnap/ecp"
+	"github.com/metricsglobal/bluesnap/vaultedshopper"
+)
+
+const vaultedShopperJSON = `{
+	"vaultedShopperId": 19549018,
+	"merchantShopperId": "shopper/7",
+	"firstName": "John",
+	"lastName": "Doe",
+	"country": "us",
+	"zip": "02453",
+	"shopperCurrency": "USD",
+	"paymentSources": {
+		"creditCardInfo": [
+			{"billingContactInfo": {"firstName": "John", "lastName": "Doe", "zip": "02453", "country": "us"},
+			 "creditCard": {"cardLastFourDigits": "1111", "cardType": "VISA", "cardSubType": "CREDIT", "expirationMonth": "07", "expirationYear": "2030"}},
+			{"creditCard": {"cardLastFourDigits": "5100", "cardType": "MASTERCARD", "cardSubType": "CREDIT"}}
+		],
+		"ecpDetails": [{"ecp": {"accountType": "CONSUMER_CHECKING", "publicAccountNumber": "5678", "publicRoutingNumber": "0345"}}],
+		"sepaDirectDebitInfo": [{"sepaDirectDebit": {"ibanFirstFour": "DE09", "ibanLastFour": "0000", "mandateId": "m-1"}}]
+	},
+	"lastPaymentInfo": {"paymentMethod": "CC", "creditCard": {"cardLastFourDigits": "5100", "cardType": "MASTERCARD"}},
+	"shippingContactInfo": {"firstName": "John", "lastName": "Doe", "address1": "1 Main St", "city": "Boston", "state": "MA", "zip": "02453", "country": "us"}
+}`
+
+func TestVaultedShopper(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(b)
+		switch {
+		case r.Method == http.MethodPost:
+			w.Header().Set("Location", "https://sandbox.bluesnap.com/services/2/vaulted-shoppers/19549018")
+			w.Write([]byte(`{"firstName":"John","lastName":"Doe"}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(vaultedShopperJSON))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+
+	created := vaultedshopper.Response{}
+	err := c.CreateVaultedShopper(vaultedshopper.Request{
+		FirstName: "John",
+		LastName:  "Doe",
+		PaymentSources: &vaultedshopper.PaymentSources{
+			CreditCardInfo: []vaultedshopper.CreditCardInfo{{
+				CreditCard: &card.CreditCardRequest{CardNumber: "4111111111111111", ExpirationMonth: "07", ExpirationYear: "2030", SecurityCode: "111"},
+			}},
+			ECPDetails: []vaultedshopper.ECPDetails{{
+				ECP: vaultedshopper.ECP{AccountNumber: "12345678", RoutingNumber: "012300345", AccountType: "CONSUMER_CHECKING"},
+			}},
+		},
+		ThreeDSecure: &card.ThreeDSecureRequest{ThreeDSecureReferenceID: "ref"},
+	}, &created, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"firstName":"John","lastName":"Doe","paymentSources":{"creditCardInfo":[{"creditCard":{"cardNumber":"4111111111111111","expirationMonth":"07","expirationYear":"2030","securityCode":"111"}}],"ecpDetails":[{"ecp":{"accountNumber":"12345678","routingNumber":"012300345","accountType":"CONSUMER_CHECKING"}}]},"threeDSecure":{"threeDSecureReferenceId":"ref"}}`
+	if method != http.MethodPost || path != "/services/2/vaulted-shoppers" || body != expected {
+		t.Errorf("unexpected create request %s %s\n%s", method, path, body)
+	}
+	if created.VaultedShopperID != 19549018 || created.Location == "" {
+		t.Errorf("expected the shopper ID from the Location header, got %d (%q)", created.VaultedShopperID, created.Location)
+	}
+
+	shopper := vaultedshopper.Response{}
+	if err := c.RetrieveVaultedShopper("19549018", &shopper, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodGet || path != "/services/2/vaulted-shoppers/19549018" {
+		t.Errorf("unexpected retrieve request %s %s", method, path)
+	}
+	ps := shopper.PaymentSources
+	if shopper.VaultedShopperID != 19549018 || len(ps.CreditCardInfo) != 2 || ps.CreditCardInfo[1].CreditCard.CardType != card.CardMastercard ||
+		len(ps.ECPDetails) != 1 || ps.ECPDetails[0].ECP.PublicAccountNumber != "5678" ||
+		len(ps.SEPADirectDebitInfo) != 1 || ps.SEPADirectDebitInfo[0].SEPADirectDebit.IBANFirstFour != "DE09" ||
+		shopper.ShippingContactInfo == nil || shopper.ShippingContactInfo.City != "Boston" {
+		t.Errorf("unexpected shopper %+v", shopper)
+	}
+	if shopper.Location != "" {
+		t.Errorf("retrieved shoppers should have no location, got %q", shopper.Location)
+	}
+
+	if err := c.RetrieveVaultedShopperByMerchantShopperID("shopper/7", &shopper, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/services/2/vaulted-shoppers/merchant/shopper%2F7" {
+		t.Errorf("unexpected retrieve by merchant shopper ID path %s", path)
+	}
+
+	if err := c.UpdateVaultedShopper("19549018", vaultedshopper.Request{Email: "john@example.com"}, &shopper, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut || path != "/services/2/vaulted-shoppers/19549018" || body != `{"email":"john@example.com"}` {
+		t.Errorf("unexpected update request %s %s\n%s", method, path, body)
+	}
+
+	if err := c.DeleteVaultedShopper("19549018", Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodDelete || path != "/services/2/vaulted-shoppers/19549018" {
+		t.Errorf("unexpected delete request %s %s", method, path)
+	}
+
+	if err := c.CreateVaultedShopper(vaultedshopper.Request{}, &card.Response{}, Opts{}); err == nil {
+		t.Error("CreateVaultedShopper should refuse a card output")
+	}
+}
+
+func TestVaultedShopperSources(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/2/vaulted-shoppers/19549018" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if r.Method == http.MethodPut {
+			bodies = append(bodies, string(b))
+		}
+		w.Write([]byte(vaultedShopperJSON))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	out := vaultedshopper.Response{}
+
+	if err := c.AddVaultedShopperCard("19549018", card.CreditCardRequest{EncryptedCardNumber: "$bsjs_0_0_1$abc", EncryptedSecurityCode: "$bsjs_0_0_1$def", ExpirationMonth: "07", ExpirationYear: "2030"},
+		&card.BillingContactInfo{Zip: "02453", Country: "us"}, &out, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddVaultedShopperCardToken("19549018", "pf-token", &out, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveVaultedShopperCard("19549018", "1111", card.CardVisa, &out, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetDefaultVaultedShopperCard("19549018", "5100", card.CardMastercard, &out, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`{"paymentSources":{"creditCardInfo":[{"creditCard":{"encryptedCardNumber":"$bsjs_0_0_1$abc","expirationMonth":"07","expirationYear":"2030","encryptedSecurityCode":"$bsjs_0_0_1$def"},"billingContactInfo":{"zip":"02453","country":"us"}}]}}`,
+		`{"pfToken":"pf-token"}`,
+		`{"paymentSources":{"creditCardInfo":[{"creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"status":"D"}]}}`,
+		`{"lastPaymentInfo":{"paymentMethod":"CC","creditCard":{"cardLastFourDigits":"5100","cardType":"MASTERCARD"}}}`,
+	}
+	if len(bodies) != len(expected) {
+		t.Fatalf("expected %d updates, got %d", len(expected), len(bodies))
+	}
+	for i := range expected {
+		if bodies[i] != expected[i] {
+			t.Errorf("update %d: expected\n%s, got\n%s", i, expected[i], bodies[i])
+		}
+	}
+
+	sources, err := c.ListVaultedShopperSources("19549018", Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 4 {
+		t.Fatalf("expected 4 sources, got %d", len(sources))
+	}
+	types := []vaultedshopper.SourceType{vaultedshopper.SourceCard, vaultedshopper.SourceCard, vaultedshopper.SourceECP, vaultedshopper.SourceSEPADirectDebit}
+	for i, s := range sources {
+		if s.Type != types[i] {
+			t.Errorf("source %d: expected type %s, got %s", i, types[i], s.Type)
+		}
+		if s.Default != (i == 1) {
+			t.Errorf("source %d: unexpected default %v", i, s.Default)
+		}
+	}
+	if sources[0].CreditCard.ExpirationMonth != "07" || sources[0].BillingContactInfo.Zip != "02453" || sources[2].ECP.PublicAccountNumber != "5678" {
+		t.Errorf("unexpected source metadata %+v", sources)
+	}
+}
+
+func TestVaultedShopperSEPADefault(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastPayment string
+		defaults    []bool
+	}{
+		{"mandate id", `{"paymentMethod": "SEPA_DIRECT_DEBIT", "sepaDirectDebit": {"mandateId": "m-2"}}`, []bool{false, true}},
+		{"iban", `{"paymentMethod": "SEPA_DIRECT_DEBIT", "sepaDirectDebit": {"ibanFirstFour": "DE09", "ibanLastFour": "0000"}}`, []bool{true, false}},
+		{"no mandate", `{"paymentMethod": "SEPA_DIRECT_DEBIT"}`, []bool{false, false}},
+		{"card", `{"paymentMethod": "CC", "creditCard": {"cardLastFourDigits": "5100", "cardType": "MASTERCARD"}}`, []bool{false, false}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var r vaultedshopper.Response
+			body := `{"paymentSources": {"sepaDirectDebitInfo": [
+				{"sepaDirectDebit": {"ibanFirstFour": "DE09", "ibanLastFour": "0000", "mandateId": "m-1"}},
+				{"sepaDirectDebit": {"ibanFirstFour": "FR76", "ibanLastFour": "0189", "mandateId": "m-2"}}
+			]}, "lastPaymentInfo": ` + test.lastPayment + `}`
+			if err := json.Unmarshal([]byte(body), &r); err != nil {
+				t.Fatal(err)
+			}
+			sources := r.Sources()
+			if len(sources) != len(test.defaults) {
+				t.Fatalf("expected %d sources, got %d", len(test.defaults), len(sources))
+			}
+			for i, s := range sources {
+				if s.Default != test.defaults[i] {
+					t.Errorf("source %d: expected default %v, got %v", i, test.defaults[i], s.Default)
+				}
+			}
+		})
+	}
+}
+
+func TestChargeVaulted(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(b))
+		switch r.URL.Path {
+		case "/services/2/vaulted-shoppers/19549018":
+			w.Write([]byte(vaultedShopperJSON))
+		case "/services/2/transactions":
+			w.Write([]byte(`{"transactionId":"1012","vaultedShopperId":19549018,"amount":25,"currency":"USD","processingInfo":{"processingStatus":"success"}}`))
+		case "/services/2/alt-transactions":
+			w.Write([]byte(`{"transactionId":"1013","vaultedShopperId":19549018,"amount":25,"currency":"USD","authorizedByShopper":true,"ecpTransaction":{"publicAccountNumber":"5678","publicRoutingNumber":"0345"},"processingInfo":{"processingStatus":"PENDING"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), srv.URL)
+	c.ValidateRequests = true
+	amount := usd("25.00")
+
+	out := card.Response{}
+	if err := c.ChargeVaulted(vaultedshopper.Charge{VaultedShopperID: 19549018, Amount: amount, MerchantTransactionID: "order-1"}, &out, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.TransactionID != "1012" {
+		t.Errorf("unexpected response %+v", out)
+	}
+
+	if err := c.AuthVaulted(vaultedshopper.Charge{
+		VaultedShopperID:     19549018,
+		Source:               vaultedshopper.CardSource("1111", card.CardVisa),
+		Amount:               amount,
+		MerchantInitiated:    true,
+		Usage:                card.UsageRecurring,
+		NetworkTransactionID: "019072416113666",
+	}, &out, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ecpOut := ecp.Response{}
+	if err := c.ChargeVaulted(vaultedshopper.Charge{
+		VaultedShopperID:    19549018,
+		Source:              vaultedshopper.ECPSource("5678", "0345"),
+		Amount:              amount,
+		AuthorizedByShopper: true,
+	}, &ecpOut, Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if ecpOut.TransactionID != "1013" || ecpOut.Amount != "25" || ecpOut.ProcessingInfo.ProcessingStatus != "PENDING" {
+		t.Errorf("unexpected response %+v", ecpOut)
+	}
+
+	expected := []string{
+		`GET /services/2/vaulted-shoppers/19549018 `,
+		`POST /services/2/transactions {"amount":25.00,"vaultedShopperId":19549018,"merchantTransactionId":"order-1","currency":"USD","creditCard":{"cardLastFourDigits":"5100","cardType":"MASTERCARD"},"cardTransactionType":"AUTH_CAPTURE","transactionId":""}`,
+		`POST /services/2/transactions {"amount":25.00,"vaultedShopperId":19549018,"currency":"USD","creditCard":{"cardLastFourDigits":"1111","cardType":"VISA"},"cardTransactionType":"AUTH_ONLY","networkTransactionInfo":{"originalNetworkTransactionId":"019072416113666"},"transactionInitiator":"MERCHANT","recurringTransaction":"RECURRING","transactionId":""}`,
+		`POST /services/2/alt-transactions {"amount":25.00,"currency":"USD","vaultedShopperId":19549018,"ecpTransaction":{"publicAccountNumber":"5678","publicRoutingNumber":"0345"},"authorizedByShopper":true}`,
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected %d requests, got %d: %v", len(expected), len(requests), requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("request %d: expected\n%s, got\n%s", i, expected[i], requests[i])
+		}
+	}
+
+	err := c.AuthVaulted(vaultedshopper.Charge{VaultedShopperID: 19549018, Source: vaultedshopper.CardSource("1111", card.CardVisa), Amount: amount, MerchantInitiated: true}, &out, Opts{})
+	if err == nil {
+		t.Error("expected an error making a merchant-initiated charge without usage")
+	}
+	err = c.AuthVaulted(vaultedshopper.Charge{VaultedShopperID: 19549018, Source: vaultedshopper.ECPSource("5678", "0345"), Amount: amount, AuthorizedByShopper: true}, &out, Opts{})
+	if err == nil {
+		t.Error("expected an error authorizing a bank account")
+	}
+	err = c.ChargeVaulted(vaultedshopper.Charge{VaultedShopperID: 19549018, Source: vaultedshopper.ECPSource("5678", "0345"), Amount: amount}, &ecpOut, Opts{})
+	if err == nil {
+		t.Error("expected an error charging a bank account without the shopper authorization")
+	}
+	err = c.ChargeVaulted(vaultedshopper.Charge{VaultedShopperID: 19549018, Source: vaultedshopper.CardSource("1111", card.CardVisa), Amount: amount}, &ecpOut, Opts{})
+	if err == nil {
+		t.Error("expected an error charging a card into an ECP response")
+	}
+	if len(requests) != len(expected) {
+		t.Errorf("unexpected requests %v", requests[len(expected):])
+	}
+}